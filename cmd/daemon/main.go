@@ -44,4 +44,5 @@ func main() {
 
 	// Start the daemon with context
 	service.RunWithContext(ctx)
+	service.Close()
 }
\ No newline at end of file
@@ -2,7 +2,6 @@ package main
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"log"
 	"os"
@@ -10,13 +9,16 @@ import (
 	"strings"
 
 	"github.com/go-redis/redis/v8"
-	"github.com/sunbankio/tronevents/pkg/scanner"
+	"github.com/sunbankio/tronevents/pkg/config"
+	"github.com/sunbankio/tronevents/pkg/consumer"
+	"github.com/sunbankio/tronevents/pkg/publisher"
 )
 
 func main() {
 	if len(os.Args) < 2 {
-		fmt.Println("Usage: go run debug_check.go <block_number> [redis_addr]")
+		fmt.Println("Usage: go run debug_check.go <block_number> [redis_addr] [stream]")
 		fmt.Println("Example: go run debug_check.go 1234567")
+		fmt.Println("The stream defaults to the events stream of $CONFIG_PATH, or tron:events")
 		os.Exit(1)
 	}
 
@@ -30,6 +32,21 @@ func main() {
 		redisAddr = os.Args[2]
 	}
 
+	// Resolve the stream name from the arguments, then the daemon configuration
+	var streams publisher.Streams
+	if len(os.Args) > 3 {
+		streams.Events = os.Args[3]
+	}
+	if configPath := os.Getenv("CONFIG_PATH"); configPath != "" && streams.Events == "" {
+		cfg, err := config.LoadFromFile(configPath)
+		if err != nil {
+			log.Fatal("Failed to load config: ", err)
+		}
+		streams = cfg.Publisher.Streams
+	}
+	streams.ApplyPrefix("")
+	streamName := streams.Events
+
 	// Create Redis client
 	rdb := redis.NewClient(&redis.Options{
 		Addr: redisAddr,
@@ -39,9 +56,9 @@ func main() {
 	ctx := context.Background()
 
 	// Check if stream exists by trying to get its length
-	streamLen, err := rdb.XLen(ctx, "tron:events").Result()
+	streamLen, err := rdb.XLen(ctx, streamName).Result()
 	if err != nil || streamLen == 0 {
-		fmt.Printf("Stream 'tron:events' doesn't exist or is empty (length: %d, error: %v)\n", streamLen, err)
+		fmt.Printf("Stream '%s' doesn't exist or is empty (length: %d, error: %v)\n", streamName, streamLen, err)
 		os.Exit(0) // Exit normally since this is not an error with the tool itself
 	}
 
@@ -50,7 +67,7 @@ func main() {
 
 	// Try to find entries related to the specific block
 	// Scan the stream looking for transactions from the specified block
-	streamEntries, err := rdb.XRange(ctx, "tron:events", "-", "+").Result()
+	streamEntries, err := rdb.XRange(ctx, streamName, "-", "+").Result()
 	if err != nil {
 		log.Printf("Error reading stream: %v", err)
 		os.Exit(1)
@@ -60,22 +77,16 @@ func main() {
 
 	foundBlock := false
 	for _, entry := range streamEntries {
-		for fieldName, value := range entry.Values {
-			if fieldName == "payload" {
-				// Try to unmarshal the payload to check if it contains the block
-				var tx scanner.Transaction
-				if err := json.Unmarshal([]byte(value.(string)), &tx); err == nil {
-					if tx.BlockNumber == blockNumber {
-						fmt.Printf("\nFound transaction from block %d:\n", blockNumber)
-						fmt.Printf("  Entry ID: %s\n", entry.ID)
-						fmt.Printf("  Transaction ID: %s\n", tx.ID)
-						fmt.Printf("  Block Number: %d\n", tx.BlockNumber)
-						fmt.Printf("  Block Timestamp: %v\n", tx.BlockTimestamp)
-						fmt.Printf("  Transaction Data: %+v\n", tx)
-						foundBlock = true
-					}
-				}
-			}
+		// Try to decode the payload to check if it contains the block
+		tx, err := consumer.DecodeTransaction(entry)
+		if err == nil && tx.BlockNumber == blockNumber {
+			fmt.Printf("\nFound transaction from block %d:\n", blockNumber)
+			fmt.Printf("  Entry ID: %s\n", entry.ID)
+			fmt.Printf("  Transaction ID: %s\n", tx.ID)
+			fmt.Printf("  Block Number: %d\n", tx.BlockNumber)
+			fmt.Printf("  Block Timestamp: %v\n", tx.BlockTimestamp)
+			fmt.Printf("  Transaction Data: %+v\n", *tx)
+			foundBlock = true
 		}
 	}
 
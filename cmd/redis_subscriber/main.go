@@ -2,19 +2,40 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
+	"os"
 	"time"
 
 	"github.com/go-redis/redis/v8"
+	"github.com/sunbankio/tronevents/pkg/config"
+	"github.com/sunbankio/tronevents/pkg/consumer"
+	"github.com/sunbankio/tronevents/pkg/publisher"
 )
 
 func main() {
-	redisStream := NewRedisStream("localhost:6379", "")
+	// Read the Redis address and stream name from the daemon configuration when CONFIG_PATH is set
+	redisAddr, redisPassword := "localhost:6379", ""
+	var streams publisher.Streams
+	if configPath := os.Getenv("CONFIG_PATH"); configPath != "" {
+		cfg, err := config.LoadFromFile(configPath)
+		if err != nil {
+			log.Fatal("Failed to load config: ", err)
+		}
+		redisAddr, redisPassword = cfg.Redis.Addr, cfg.Redis.Password
+		streams = cfg.Publisher.Streams
+	}
+	if stream := os.Getenv("STREAM"); stream != "" {
+		streams.Events = stream
+	}
+	streams.ApplyPrefix("")
+
+	redisStream := NewRedisStream(redisAddr, redisPassword)
 
 	processor := &EventProcessor{
 		redisStream:  redisStream,
-		streamName:   "tron:events",
+		streamName:   streams.Events,
 		groupName:    "tronevents_group",    // Hardcoded consumer group
 		consumerName: "tronevents_consumer", // Hardcoded consumer name
 	}
@@ -68,7 +89,15 @@ func (ep *EventProcessor) ProcessEvents(ctx context.Context) error {
 
 func (ep *EventProcessor) processMessage(msg redis.XMessage) error {
 	// Your message processing logic here
-	fmt.Printf("Processing message ID: %s, Values: %v\n", msg.ID, msg.Values)
+	tx, err := consumer.DecodeTransaction(msg)
+	if errors.Is(err, consumer.ErrNoPayload) {
+		fmt.Printf("Processing message ID: %s, Values: %v\n", msg.ID, msg.Values)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Processing message ID: %s, Transaction: %s (block %d)\n", msg.ID, tx.ID, tx.BlockNumber)
 	return nil
 }
 
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+
+	"github.com/sunbankio/tronevents/pkg/config"
+	"github.com/sunbankio/tronevents/pkg/publisher"
+	redisPkg "github.com/sunbankio/tronevents/pkg/redis"
+)
+
+const usage = `usage:
+  watchlist add [-tenant name] address...
+  watchlist remove address...
+  watchlist list`
+
+// watchlist manages the addresses whose transactions the publisher copies to the watch streams.
+func main() {
+	if len(os.Args) < 2 {
+		log.Fatal(usage)
+	}
+
+	configPath := os.Getenv("CONFIG_PATH")
+	if configPath == "" {
+		configPath = "config.yaml" // default config file
+	}
+
+	cfg, err := config.LoadFromFile(configPath)
+	if err != nil {
+		log.Fatal("Failed to load config: ", err)
+	}
+
+	client, err := redisPkg.NewClient(cfg.Redis)
+	if err != nil {
+		log.Fatal("Failed to connect to Redis: ", err)
+	}
+	defer client.Close()
+
+	ctx := context.Background()
+	watchlist := publisher.NewWatchlist(client, cfg.Publisher.Watchlist.Key)
+	switch os.Args[1] {
+	case "add":
+		flags := flag.NewFlagSet("add", flag.ExitOnError)
+		tenant := flags.String("tenant", "", "tenant stream of the addresses; empty for a stream per address")
+		flags.Parse(os.Args[2:])
+		if err := watchlist.Add(ctx, *tenant, flags.Args()...); err != nil {
+			log.Fatal("Failed to add addresses: ", err)
+		}
+		log.Printf("Watching %d addresses", flags.NArg())
+	case "remove":
+		removed, err := watchlist.Remove(ctx, os.Args[2:]...)
+		if err != nil {
+			log.Fatal("Failed to remove addresses: ", err)
+		}
+		log.Printf("Removed %d addresses", removed)
+	case "list":
+		tenants, err := watchlist.List(ctx)
+		if err != nil {
+			log.Fatal("Failed to list addresses: ", err)
+		}
+		addresses := make([]string, 0, len(tenants))
+		for address := range tenants {
+			addresses = append(addresses, address)
+		}
+		sort.Strings(addresses)
+		for _, address := range addresses {
+			fmt.Printf("%s\t%s\n", address, tenants[address])
+		}
+	default:
+		log.Fatal(usage)
+	}
+}
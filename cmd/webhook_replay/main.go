@@ -0,0 +1,48 @@
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+
+	"github.com/sunbankio/tronevents/pkg/config"
+	"github.com/sunbankio/tronevents/pkg/publisher"
+	redisPkg "github.com/sunbankio/tronevents/pkg/redis"
+)
+
+// webhook_replay retries the deliveries parked in the dead-letter lists of the configured webhook sinks.
+func main() {
+	configPath := os.Getenv("CONFIG_PATH")
+	if configPath == "" {
+		configPath = "config.yaml" // default config file
+	}
+
+	cfg, err := config.LoadFromFile(configPath)
+	if err != nil {
+		log.Fatal("Failed to load config: ", err)
+	}
+
+	client, err := redisPkg.NewClient(cfg.Redis)
+	if err != nil {
+		log.Fatal("Failed to connect to Redis: ", err)
+	}
+	defer client.Close()
+
+	ctx := context.Background()
+	for _, sinkConfig := range cfg.Sinks {
+		if sinkConfig.Type != publisher.SinkTypeWebhook {
+			continue
+		}
+
+		sink, err := publisher.NewWebhookReplayer(sinkConfig.Webhook, client)
+		if err != nil {
+			log.Fatal("Failed to create webhook sink: ", err)
+		}
+		replayed, err := sink.ReplayDeadLetters(ctx)
+		sink.Close()
+		if err != nil {
+			log.Fatalf("Failed to replay dead letters for %s: %v", sinkConfig.Webhook.URL, err)
+		}
+		log.Printf("Replayed %d deliveries to %s", replayed, sinkConfig.Webhook.URL)
+	}
+}
@@ -0,0 +1,105 @@
+package codec
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/sunbankio/tronevents/pkg/models"
+	"github.com/sunbankio/tronevents/pkg/scanner"
+)
+
+const (
+	// FormatCloudEvents encodes payloads as CloudEvents 1.0 structured-mode JSON events with the document as data.
+	FormatCloudEvents = "cloudevents"
+
+	// CloudEventsContentType is the media type of a structured-mode CloudEvent.
+	CloudEventsContentType = "application/cloudevents+json"
+	// CloudEventsBatchContentType is the media type of a JSON array of CloudEvents.
+	CloudEventsBatchContentType = "application/cloudevents-batch+json"
+
+	cloudEventsSpecVersion = "1.0"
+	cloudEventsTypePrefix  = "io.tron."
+)
+
+// CloudEvent is a CloudEvents 1.0 event in structured-mode JSON.
+type CloudEvent struct {
+	SpecVersion     string          `json:"specversion"`
+	Type            string          `json:"type"`   // e.g. io.tron.transaction.TriggerSmartContract, io.tron.log.Transfer or io.tron.block
+	Source          string          `json:"source"` // the network, e.g. mainnet
+	ID              string          `json:"id"`     // the transaction ID, "<txid>-<log index>" or block hash
+	Time            string          `json:"time,omitempty"`
+	Subject         string          `json:"subject,omitempty"` // the owner address, contract address or block number
+	DataContentType string          `json:"datacontenttype"`
+	Data            json.RawMessage `json:"data"`
+}
+
+// TransactionEvent wraps a transaction in a CloudEvent of the given source.
+func TransactionEvent(tx *models.SafeTransaction, source string) (*CloudEvent, error) {
+	data, err := json.Marshal(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	eventType := cloudEventsTypePrefix + "transaction"
+	var owner string
+	if tx.Contract != nil {
+		eventType += "." + tx.Contract.Type
+		owner = (&scanner.Transaction{Contract: tx.Contract}).OwnerAddress()
+	}
+	return newCloudEvent(eventType, source, tx.ID, tx.BlockTimestamp.Time, owner, data), nil
+}
+
+// LogEvent wraps a decoded log in a CloudEvent of the given source.
+func LogEvent(event *models.LogEvent, source string) (*CloudEvent, error) {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return nil, err
+	}
+
+	eventType := cloudEventsTypePrefix + "log"
+	if event.EventName != "" {
+		eventType += "." + event.EventName
+	}
+	id := event.TxID + "-" + strconv.Itoa(event.LogIndex)
+	return newCloudEvent(eventType, source, id, event.BlockTimestamp.Time, event.Address, data), nil
+}
+
+// BlockStatsEvent wraps the statistics of a block in a CloudEvent of the given source.
+func BlockStatsEvent(stats *scanner.BlockStats, source string) (*CloudEvent, error) {
+	data, err := json.Marshal(stats)
+	if err != nil {
+		return nil, err
+	}
+	subject := strconv.FormatInt(stats.BlockNumber, 10)
+	return newCloudEvent(cloudEventsTypePrefix+"block", source, stats.BlockHash, stats.BlockTimestamp, subject, data), nil
+}
+
+func newCloudEvent(eventType, source, id string, t time.Time, subject string, data []byte) *CloudEvent {
+	event := &CloudEvent{
+		SpecVersion:     cloudEventsSpecVersion,
+		Type:            eventType,
+		Source:          source,
+		ID:              id,
+		Subject:         subject,
+		DataContentType: "application/json",
+		Data:            data,
+	}
+	if timeMillis(t) != 0 {
+		event.Time = t.UTC().Format(time.RFC3339Nano)
+	}
+	return event
+}
+
+// decodeCloudEvent unmarshals a CloudEvent and its data into v.
+func decodeCloudEvent(payload []byte, v interface{}) error {
+	var event CloudEvent
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return err
+	}
+	if event.SpecVersion != cloudEventsSpecVersion {
+		return fmt.Errorf("unsupported CloudEvents specversion %q", event.SpecVersion)
+	}
+	return json.Unmarshal(event.Data, v)
+}
@@ -0,0 +1,310 @@
+// Package codec encodes and decodes the payloads of the tronevents stream entries.
+//
+// A payload is a JSON, MessagePack or Protocol Buffers document, or a CloudEvent wrapping the JSON
+// document, optionally compressed with zstd.
+// The encoding is recorded next to the payload as a string such as "json" or "protobuf+zstd".
+// The Protocol Buffers schema is published in tronevents.proto, with the generated types in package pb.
+package codec
+
+//go:generate protoc --go_out=. --go_opt=module=github.com/sunbankio/tronevents/pkg/codec tronevents.proto
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/sunbankio/tronevents/pkg/models"
+	"github.com/sunbankio/tronevents/pkg/scanner"
+	"github.com/vmihailenco/msgpack/v5"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	// FormatJSON encodes payloads as JSON documents. It is the default.
+	FormatJSON = "json"
+	// FormatMsgPack encodes payloads as MessagePack documents with the same structure as the JSON documents.
+	FormatMsgPack = "msgpack"
+	// FormatProtobuf encodes payloads as the Protocol Buffers messages of tronevents.proto.
+	FormatProtobuf = "protobuf"
+
+	// CompressionZstd compresses the encoded payloads with zstd.
+	CompressionZstd = "zstd"
+)
+
+// Encoding is a payload format with an optional compression.
+type Encoding struct {
+	Format      string
+	Compression string
+	Source      string // CloudEvents source of the encoded events, not part of the encoding string
+}
+
+// JSON is the encoding of uncompressed JSON payloads, used by entries without an encoding.
+var JSON = Encoding{Format: FormatJSON}
+
+// Parse parses an encoding string such as "msgpack" or "protobuf+zstd". An empty string is JSON.
+func Parse(s string) (Encoding, error) {
+	if s == "" {
+		return JSON, nil
+	}
+	format, compression, _ := strings.Cut(s, "+")
+	e := Encoding{Format: format, Compression: compression}
+	if err := e.Validate(); err != nil {
+		return Encoding{}, err
+	}
+	return e, nil
+}
+
+// String returns the encoding string recorded with the payloads.
+func (e Encoding) String() string {
+	format := e.Format
+	if format == "" {
+		format = FormatJSON
+	}
+	if e.Compression == "" {
+		return format
+	}
+	return format + "+" + e.Compression
+}
+
+// Validate checks the encoding for unknown formats and compressions.
+func (e Encoding) Validate() error {
+	switch e.Format {
+	case "", FormatJSON, FormatMsgPack, FormatProtobuf, FormatCloudEvents:
+	default:
+		return fmt.Errorf("unknown payload format %q", e.Format)
+	}
+	switch e.Compression {
+	case "", CompressionZstd:
+	default:
+		return fmt.Errorf("unknown payload compression %q", e.Compression)
+	}
+	return nil
+}
+
+// EncodeTransaction encodes a transaction payload.
+func (e Encoding) EncodeTransaction(tx *models.SafeTransaction) ([]byte, error) {
+	if e.Format == FormatCloudEvents {
+		event, err := TransactionEvent(tx, e.Source)
+		if err != nil {
+			return nil, err
+		}
+		return e.encode(event, nil)
+	}
+	return e.encode(tx, func() (proto.Message, error) { return transactionMessage(tx) })
+}
+
+// EncodeLog encodes a log payload.
+func (e Encoding) EncodeLog(event *models.LogEvent) ([]byte, error) {
+	if e.Format == FormatCloudEvents {
+		cloudEvent, err := LogEvent(event, e.Source)
+		if err != nil {
+			return nil, err
+		}
+		return e.encode(cloudEvent, nil)
+	}
+	return e.encode(event, func() (proto.Message, error) { return logEventMessage(event) })
+}
+
+// EncodeBlockStats encodes a block statistics payload.
+func (e Encoding) EncodeBlockStats(stats *scanner.BlockStats) ([]byte, error) {
+	if e.Format == FormatCloudEvents {
+		event, err := BlockStatsEvent(stats, e.Source)
+		if err != nil {
+			return nil, err
+		}
+		return e.encode(event, nil)
+	}
+	return e.encode(stats, func() (proto.Message, error) { return blockStatsMessage(stats), nil })
+}
+
+// EncodeRaw encodes a raw protobufs payload. Raw payloads have no CloudEvents form.
+func (e Encoding) EncodeRaw(raw *scanner.RawData) ([]byte, error) {
+	if e.Format == FormatCloudEvents {
+		return nil, fmt.Errorf("raw payloads cannot be encoded as %s", FormatCloudEvents)
+	}
+	return e.encode(raw, func() (proto.Message, error) { return rawDataMessage(raw), nil })
+}
+
+// DecodeTransaction decodes a transaction payload.
+func (e Encoding) DecodeTransaction(payload []byte) (*models.SafeTransaction, error) {
+	tx := new(models.SafeTransaction)
+	if err := e.decode(payload, tx, func(b []byte) error { return parseTransaction(b, tx) }); err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+// DecodeLog decodes a log payload.
+func (e Encoding) DecodeLog(payload []byte) (*models.LogEvent, error) {
+	event := new(models.LogEvent)
+	if err := e.decode(payload, event, func(b []byte) error { return parseLogEvent(b, event) }); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// DecodeBlockStats decodes a block statistics payload.
+func (e Encoding) DecodeBlockStats(payload []byte) (*scanner.BlockStats, error) {
+	stats := new(scanner.BlockStats)
+	if err := e.decode(payload, stats, func(b []byte) error { return parseBlockStats(b, stats) }); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// DecodeRaw decodes a raw protobufs payload.
+func (e Encoding) DecodeRaw(payload []byte) (*scanner.RawData, error) {
+	raw := new(scanner.RawData)
+	if err := e.decode(payload, raw, func(b []byte) error { return parseRawData(b, raw) }); err != nil {
+		return nil, err
+	}
+	return raw, nil
+}
+
+// encode marshals v in the encoding format, or the message returned by protoMessage for Protocol Buffers,
+// and compresses it. CloudEvents are marshaled as JSON.
+func (e Encoding) encode(v interface{}, protoMessage func() (proto.Message, error)) ([]byte, error) {
+	var payload []byte
+	var err error
+	switch e.Format {
+	case "", FormatJSON, FormatCloudEvents:
+		payload, err = json.Marshal(v)
+	case FormatMsgPack:
+		payload, err = marshalMsgPack(v)
+	case FormatProtobuf:
+		var message proto.Message
+		if message, err = protoMessage(); err == nil {
+			payload, err = protoMarshal.Marshal(message)
+		}
+	default:
+		err = fmt.Errorf("unknown payload format %q", e.Format)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	switch e.Compression {
+	case "":
+		return payload, nil
+	case CompressionZstd:
+		return zstdEncoder().EncodeAll(payload, nil), nil
+	default:
+		return nil, fmt.Errorf("unknown payload compression %q", e.Compression)
+	}
+}
+
+// decode decompresses the payload and unmarshals it into v, using parseProto for Protocol Buffers.
+func (e Encoding) decode(payload []byte, v interface{}, parseProto func([]byte) error) error {
+	switch e.Compression {
+	case "":
+	case CompressionZstd:
+		decoded, err := zstdDecoder().DecodeAll(payload, nil)
+		if err != nil {
+			return fmt.Errorf("decompress payload: %w", err)
+		}
+		payload = decoded
+	default:
+		return fmt.Errorf("unknown payload compression %q", e.Compression)
+	}
+
+	switch e.Format {
+	case "", FormatJSON:
+		return json.Unmarshal(payload, v)
+	case FormatMsgPack:
+		return unmarshalMsgPack(payload, v)
+	case FormatProtobuf:
+		return parseProto(payload)
+	case FormatCloudEvents:
+		return decodeCloudEvent(payload, v)
+	default:
+		return fmt.Errorf("unknown payload format %q", e.Format)
+	}
+}
+
+// marshalMsgPack encodes the JSON document of v as MessagePack, so both formats share one structure.
+func marshalMsgPack(v interface{}) ([]byte, error) {
+	document, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(document))
+	decoder.UseNumber()
+	var generic interface{}
+	if err := decoder.Decode(&generic); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	encoder := msgpack.NewEncoder(&buf)
+	encoder.UseCompactInts(true)
+	if err := encoder.Encode(msgpackValue(generic)); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// msgpackValue replaces the JSON numbers of a document with integers where possible, or floats.
+// Integers beyond 64 bits are kept as strings rather than losing precision.
+func msgpackValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for key, value := range v {
+			v[key] = msgpackValue(value)
+		}
+	case []interface{}:
+		for i, value := range v {
+			v[i] = msgpackValue(value)
+		}
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+		if strings.ContainsAny(v.String(), ".eE") {
+			if f, err := v.Float64(); err == nil {
+				return f
+			}
+		}
+		return v.String()
+	}
+	return v
+}
+
+// unmarshalMsgPack decodes a MessagePack document into v through its JSON structure.
+func unmarshalMsgPack(payload []byte, v interface{}) error {
+	var generic interface{}
+	if err := msgpack.Unmarshal(payload, &generic); err != nil {
+		return err
+	}
+	document, err := json.Marshal(generic)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(document, v)
+}
+
+var (
+	zstdOnce sync.Once
+	zstdEnc  *zstd.Encoder
+	zstdDec  *zstd.Decoder
+)
+
+// zstdEncoder returns the shared zstd encoder; EncodeAll is safe for concurrent use.
+func zstdEncoder() *zstd.Encoder {
+	zstdOnce.Do(initZstd)
+	return zstdEnc
+}
+
+// zstdDecoder returns the shared zstd decoder; DecodeAll is safe for concurrent use.
+func zstdDecoder() *zstd.Decoder {
+	zstdOnce.Do(initZstd)
+	return zstdDec
+}
+
+func initZstd() {
+	// Neither constructor fails without options that can be invalid
+	zstdEnc, _ = zstd.NewWriter(nil)
+	zstdDec, _ = zstd.NewReader(nil)
+}
@@ -0,0 +1,1136 @@
+// Protocol Buffers schema of the tronevents payloads with the "protobuf" encoding.
+//
+// Timestamps are Unix milliseconds, 0 when unknown. Values whose type depends on the
+// contract or event (contract parameters, event inputs) are google.protobuf.Struct and
+// google.protobuf.Value documents with the structure of the JSON payloads; integers that
+// a double can't hold exactly, such as uint256 amounts, are strings.
+//
+// The Go types in pkg/codec/pb are generated with protoc-gen-go, see go:generate in codec.go.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.8
+// 	protoc        (unknown)
+// source: tronevents.proto
+
+package pb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	structpb "google.golang.org/protobuf/types/known/structpb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Transaction is the payload of the events stream and of routed streams.
+type Transaction struct {
+	state                protoimpl.MessageState `protogen:"open.v1"`
+	Id                   string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Contract             *Contract              `protobuf:"bytes,2,opt,name=contract,proto3" json:"contract,omitempty"`
+	Ret                  *RetInfo               `protobuf:"bytes,3,opt,name=ret,proto3" json:"ret,omitempty"`
+	TimestampMs          int64                  `protobuf:"varint,4,opt,name=timestamp_ms,json=timestampMs,proto3" json:"timestamp_ms,omitempty"`
+	BlockNumber          int64                  `protobuf:"varint,5,opt,name=block_number,json=blockNumber,proto3" json:"block_number,omitempty"`
+	BlockTimestampMs     int64                  `protobuf:"varint,6,opt,name=block_timestamp_ms,json=blockTimestampMs,proto3" json:"block_timestamp_ms,omitempty"`
+	ExpirationMs         int64                  `protobuf:"varint,7,opt,name=expiration_ms,json=expirationMs,proto3" json:"expiration_ms,omitempty"`
+	Receipt              *Receipt               `protobuf:"bytes,8,opt,name=receipt,proto3" json:"receipt,omitempty"`
+	Logs                 []*LogInfo             `protobuf:"bytes,9,rep,name=logs,proto3" json:"logs,omitempty"`
+	Signers              []string               `protobuf:"bytes,10,rep,name=signers,proto3" json:"signers,omitempty"`
+	Raw                  *RawData               `protobuf:"bytes,11,opt,name=raw,proto3" json:"raw,omitempty"`
+	InternalTransactions []*InternalTransaction `protobuf:"bytes,12,rep,name=internal_transactions,json=internalTransactions,proto3" json:"internal_transactions,omitempty"`
+	Index                int32                  `protobuf:"varint,13,opt,name=index,proto3" json:"index,omitempty"` // position of the transaction in its block
+	unknownFields        protoimpl.UnknownFields
+	sizeCache            protoimpl.SizeCache
+}
+
+func (x *Transaction) Reset() {
+	*x = Transaction{}
+	mi := &file_tronevents_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Transaction) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Transaction) ProtoMessage() {}
+
+func (x *Transaction) ProtoReflect() protoreflect.Message {
+	mi := &file_tronevents_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Transaction.ProtoReflect.Descriptor instead.
+func (*Transaction) Descriptor() ([]byte, []int) {
+	return file_tronevents_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Transaction) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Transaction) GetContract() *Contract {
+	if x != nil {
+		return x.Contract
+	}
+	return nil
+}
+
+func (x *Transaction) GetRet() *RetInfo {
+	if x != nil {
+		return x.Ret
+	}
+	return nil
+}
+
+func (x *Transaction) GetTimestampMs() int64 {
+	if x != nil {
+		return x.TimestampMs
+	}
+	return 0
+}
+
+func (x *Transaction) GetBlockNumber() int64 {
+	if x != nil {
+		return x.BlockNumber
+	}
+	return 0
+}
+
+func (x *Transaction) GetBlockTimestampMs() int64 {
+	if x != nil {
+		return x.BlockTimestampMs
+	}
+	return 0
+}
+
+func (x *Transaction) GetExpirationMs() int64 {
+	if x != nil {
+		return x.ExpirationMs
+	}
+	return 0
+}
+
+func (x *Transaction) GetReceipt() *Receipt {
+	if x != nil {
+		return x.Receipt
+	}
+	return nil
+}
+
+func (x *Transaction) GetLogs() []*LogInfo {
+	if x != nil {
+		return x.Logs
+	}
+	return nil
+}
+
+func (x *Transaction) GetSigners() []string {
+	if x != nil {
+		return x.Signers
+	}
+	return nil
+}
+
+func (x *Transaction) GetRaw() *RawData {
+	if x != nil {
+		return x.Raw
+	}
+	return nil
+}
+
+func (x *Transaction) GetInternalTransactions() []*InternalTransaction {
+	if x != nil {
+		return x.InternalTransactions
+	}
+	return nil
+}
+
+func (x *Transaction) GetIndex() int32 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+type Contract struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Type  string                 `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`
+	// contract parameter, e.g. {"owner_address":"T...","amount":1000000}
+	Parameter     *structpb.Struct `protobuf:"bytes,4,opt,name=parameter,proto3" json:"parameter,omitempty"`
+	PermissionId  int32            `protobuf:"varint,3,opt,name=permission_id,json=permissionId,proto3" json:"permission_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Contract) Reset() {
+	*x = Contract{}
+	mi := &file_tronevents_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Contract) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Contract) ProtoMessage() {}
+
+func (x *Contract) ProtoReflect() protoreflect.Message {
+	mi := &file_tronevents_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Contract.ProtoReflect.Descriptor instead.
+func (*Contract) Descriptor() ([]byte, []int) {
+	return file_tronevents_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Contract) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *Contract) GetParameter() *structpb.Struct {
+	if x != nil {
+		return x.Parameter
+	}
+	return nil
+}
+
+func (x *Contract) GetPermissionId() int32 {
+	if x != nil {
+		return x.PermissionId
+	}
+	return 0
+}
+
+type RetInfo struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ContractRet   string                 `protobuf:"bytes,1,opt,name=contract_ret,json=contractRet,proto3" json:"contract_ret,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RetInfo) Reset() {
+	*x = RetInfo{}
+	mi := &file_tronevents_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RetInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RetInfo) ProtoMessage() {}
+
+func (x *RetInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_tronevents_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RetInfo.ProtoReflect.Descriptor instead.
+func (*RetInfo) Descriptor() ([]byte, []int) {
+	return file_tronevents_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *RetInfo) GetContractRet() string {
+	if x != nil {
+		return x.ContractRet
+	}
+	return ""
+}
+
+type Receipt struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	EnergyUsage       int64                  `protobuf:"varint,1,opt,name=energy_usage,json=energyUsage,proto3" json:"energy_usage,omitempty"`
+	EnergyFee         int64                  `protobuf:"varint,2,opt,name=energy_fee,json=energyFee,proto3" json:"energy_fee,omitempty"`
+	OriginEnergyUsage int64                  `protobuf:"varint,3,opt,name=origin_energy_usage,json=originEnergyUsage,proto3" json:"origin_energy_usage,omitempty"`
+	EnergyUsageTotal  int64                  `protobuf:"varint,4,opt,name=energy_usage_total,json=energyUsageTotal,proto3" json:"energy_usage_total,omitempty"`
+	NetUsage          int64                  `protobuf:"varint,5,opt,name=net_usage,json=netUsage,proto3" json:"net_usage,omitempty"`
+	NetFee            int64                  `protobuf:"varint,6,opt,name=net_fee,json=netFee,proto3" json:"net_fee,omitempty"`
+	Result            string                 `protobuf:"bytes,7,opt,name=result,proto3" json:"result,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *Receipt) Reset() {
+	*x = Receipt{}
+	mi := &file_tronevents_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Receipt) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Receipt) ProtoMessage() {}
+
+func (x *Receipt) ProtoReflect() protoreflect.Message {
+	mi := &file_tronevents_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Receipt.ProtoReflect.Descriptor instead.
+func (*Receipt) Descriptor() ([]byte, []int) {
+	return file_tronevents_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *Receipt) GetEnergyUsage() int64 {
+	if x != nil {
+		return x.EnergyUsage
+	}
+	return 0
+}
+
+func (x *Receipt) GetEnergyFee() int64 {
+	if x != nil {
+		return x.EnergyFee
+	}
+	return 0
+}
+
+func (x *Receipt) GetOriginEnergyUsage() int64 {
+	if x != nil {
+		return x.OriginEnergyUsage
+	}
+	return 0
+}
+
+func (x *Receipt) GetEnergyUsageTotal() int64 {
+	if x != nil {
+		return x.EnergyUsageTotal
+	}
+	return 0
+}
+
+func (x *Receipt) GetNetUsage() int64 {
+	if x != nil {
+		return x.NetUsage
+	}
+	return 0
+}
+
+func (x *Receipt) GetNetFee() int64 {
+	if x != nil {
+		return x.NetFee
+	}
+	return 0
+}
+
+func (x *Receipt) GetResult() string {
+	if x != nil {
+		return x.Result
+	}
+	return ""
+}
+
+type InternalTransaction struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	CallerAddress string                 `protobuf:"bytes,1,opt,name=caller_address,json=callerAddress,proto3" json:"caller_address,omitempty"`
+	ToAddress     string                 `protobuf:"bytes,2,opt,name=to_address,json=toAddress,proto3" json:"to_address,omitempty"`
+	CallValue     int64                  `protobuf:"varint,3,opt,name=call_value,json=callValue,proto3" json:"call_value,omitempty"` // TRX transferred, in sun
+	Rejected      bool                   `protobuf:"varint,4,opt,name=rejected,proto3" json:"rejected,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *InternalTransaction) Reset() {
+	*x = InternalTransaction{}
+	mi := &file_tronevents_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *InternalTransaction) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InternalTransaction) ProtoMessage() {}
+
+func (x *InternalTransaction) ProtoReflect() protoreflect.Message {
+	mi := &file_tronevents_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InternalTransaction.ProtoReflect.Descriptor instead.
+func (*InternalTransaction) Descriptor() ([]byte, []int) {
+	return file_tronevents_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *InternalTransaction) GetCallerAddress() string {
+	if x != nil {
+		return x.CallerAddress
+	}
+	return ""
+}
+
+func (x *InternalTransaction) GetToAddress() string {
+	if x != nil {
+		return x.ToAddress
+	}
+	return ""
+}
+
+func (x *InternalTransaction) GetCallValue() int64 {
+	if x != nil {
+		return x.CallValue
+	}
+	return 0
+}
+
+func (x *InternalTransaction) GetRejected() bool {
+	if x != nil {
+		return x.Rejected
+	}
+	return false
+}
+
+type LogInfo struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	EventName     string                 `protobuf:"bytes,1,opt,name=event_name,json=eventName,proto3" json:"event_name,omitempty"`
+	Signature     string                 `protobuf:"bytes,2,opt,name=signature,proto3" json:"signature,omitempty"`
+	Inputs        []*EventInput          `protobuf:"bytes,3,rep,name=inputs,proto3" json:"inputs,omitempty"`
+	Address       string                 `protobuf:"bytes,4,opt,name=address,proto3" json:"address,omitempty"`
+	Token         *TokenInfo             `protobuf:"bytes,5,opt,name=token,proto3" json:"token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LogInfo) Reset() {
+	*x = LogInfo{}
+	mi := &file_tronevents_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LogInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LogInfo) ProtoMessage() {}
+
+func (x *LogInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_tronevents_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LogInfo.ProtoReflect.Descriptor instead.
+func (*LogInfo) Descriptor() ([]byte, []int) {
+	return file_tronevents_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *LogInfo) GetEventName() string {
+	if x != nil {
+		return x.EventName
+	}
+	return ""
+}
+
+func (x *LogInfo) GetSignature() string {
+	if x != nil {
+		return x.Signature
+	}
+	return ""
+}
+
+func (x *LogInfo) GetInputs() []*EventInput {
+	if x != nil {
+		return x.Inputs
+	}
+	return nil
+}
+
+func (x *LogInfo) GetAddress() string {
+	if x != nil {
+		return x.Address
+	}
+	return ""
+}
+
+func (x *LogInfo) GetToken() *TokenInfo {
+	if x != nil {
+		return x.Token
+	}
+	return nil
+}
+
+type EventInput struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Name  string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Type  string                 `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"`
+	// value of the input, e.g. "T..." for an address
+	Value *structpb.Value `protobuf:"bytes,4,opt,name=value,proto3" json:"value,omitempty"`
+	// whether the input is an indexed parameter, carried in a topic of the log
+	Indexed       bool `protobuf:"varint,5,opt,name=indexed,proto3" json:"indexed,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EventInput) Reset() {
+	*x = EventInput{}
+	mi := &file_tronevents_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EventInput) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EventInput) ProtoMessage() {}
+
+func (x *EventInput) ProtoReflect() protoreflect.Message {
+	mi := &file_tronevents_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EventInput.ProtoReflect.Descriptor instead.
+func (*EventInput) Descriptor() ([]byte, []int) {
+	return file_tronevents_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *EventInput) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *EventInput) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *EventInput) GetValue() *structpb.Value {
+	if x != nil {
+		return x.Value
+	}
+	return nil
+}
+
+func (x *EventInput) GetIndexed() bool {
+	if x != nil {
+		return x.Indexed
+	}
+	return false
+}
+
+type TokenInfo struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Symbol        string                 `protobuf:"bytes,2,opt,name=symbol,proto3" json:"symbol,omitempty"`
+	Decimals      int32                  `protobuf:"varint,3,opt,name=decimals,proto3" json:"decimals,omitempty"`
+	Amount        string                 `protobuf:"bytes,4,opt,name=amount,proto3" json:"amount,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TokenInfo) Reset() {
+	*x = TokenInfo{}
+	mi := &file_tronevents_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TokenInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TokenInfo) ProtoMessage() {}
+
+func (x *TokenInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_tronevents_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TokenInfo.ProtoReflect.Descriptor instead.
+func (*TokenInfo) Descriptor() ([]byte, []int) {
+	return file_tronevents_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *TokenInfo) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *TokenInfo) GetSymbol() string {
+	if x != nil {
+		return x.Symbol
+	}
+	return ""
+}
+
+func (x *TokenInfo) GetDecimals() int32 {
+	if x != nil {
+		return x.Decimals
+	}
+	return 0
+}
+
+func (x *TokenInfo) GetAmount() string {
+	if x != nil {
+		return x.Amount
+	}
+	return ""
+}
+
+// LogEvent is the payload of the logs stream: one decoded log of a transaction.
+type LogEvent struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Txid             string                 `protobuf:"bytes,1,opt,name=txid,proto3" json:"txid,omitempty"`
+	BlockNumber      int64                  `protobuf:"varint,2,opt,name=block_number,json=blockNumber,proto3" json:"block_number,omitempty"`
+	BlockTimestampMs int64                  `protobuf:"varint,3,opt,name=block_timestamp_ms,json=blockTimestampMs,proto3" json:"block_timestamp_ms,omitempty"`
+	TxIndex          int32                  `protobuf:"varint,4,opt,name=tx_index,json=txIndex,proto3" json:"tx_index,omitempty"`    // position of the transaction in its block
+	LogIndex         int32                  `protobuf:"varint,5,opt,name=log_index,json=logIndex,proto3" json:"log_index,omitempty"` // position of the log in its transaction
+	Address          string                 `protobuf:"bytes,6,opt,name=address,proto3" json:"address,omitempty"`                    // contract emitting the event
+	EventName        string                 `protobuf:"bytes,7,opt,name=event_name,json=eventName,proto3" json:"event_name,omitempty"`
+	Signature        string                 `protobuf:"bytes,8,opt,name=signature,proto3" json:"signature,omitempty"`
+	Inputs           []*EventInput          `protobuf:"bytes,9,rep,name=inputs,proto3" json:"inputs,omitempty"`
+	Token            *TokenInfo             `protobuf:"bytes,10,opt,name=token,proto3" json:"token,omitempty"`
+	Success          bool                   `protobuf:"varint,11,opt,name=success,proto3" json:"success,omitempty"` // whether the transaction succeeded
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *LogEvent) Reset() {
+	*x = LogEvent{}
+	mi := &file_tronevents_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LogEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LogEvent) ProtoMessage() {}
+
+func (x *LogEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_tronevents_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LogEvent.ProtoReflect.Descriptor instead.
+func (*LogEvent) Descriptor() ([]byte, []int) {
+	return file_tronevents_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *LogEvent) GetTxid() string {
+	if x != nil {
+		return x.Txid
+	}
+	return ""
+}
+
+func (x *LogEvent) GetBlockNumber() int64 {
+	if x != nil {
+		return x.BlockNumber
+	}
+	return 0
+}
+
+func (x *LogEvent) GetBlockTimestampMs() int64 {
+	if x != nil {
+		return x.BlockTimestampMs
+	}
+	return 0
+}
+
+func (x *LogEvent) GetTxIndex() int32 {
+	if x != nil {
+		return x.TxIndex
+	}
+	return 0
+}
+
+func (x *LogEvent) GetLogIndex() int32 {
+	if x != nil {
+		return x.LogIndex
+	}
+	return 0
+}
+
+func (x *LogEvent) GetAddress() string {
+	if x != nil {
+		return x.Address
+	}
+	return ""
+}
+
+func (x *LogEvent) GetEventName() string {
+	if x != nil {
+		return x.EventName
+	}
+	return ""
+}
+
+func (x *LogEvent) GetSignature() string {
+	if x != nil {
+		return x.Signature
+	}
+	return ""
+}
+
+func (x *LogEvent) GetInputs() []*EventInput {
+	if x != nil {
+		return x.Inputs
+	}
+	return nil
+}
+
+func (x *LogEvent) GetToken() *TokenInfo {
+	if x != nil {
+		return x.Token
+	}
+	return nil
+}
+
+func (x *LogEvent) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+// RawData is the payload of the raw stream, and the raw field of inline transactions.
+type RawData struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Transaction     string                 `protobuf:"bytes,1,opt,name=transaction,proto3" json:"transaction,omitempty"`
+	TransactionInfo string                 `protobuf:"bytes,2,opt,name=transaction_info,json=transactionInfo,proto3" json:"transaction_info,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *RawData) Reset() {
+	*x = RawData{}
+	mi := &file_tronevents_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RawData) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RawData) ProtoMessage() {}
+
+func (x *RawData) ProtoReflect() protoreflect.Message {
+	mi := &file_tronevents_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RawData.ProtoReflect.Descriptor instead.
+func (*RawData) Descriptor() ([]byte, []int) {
+	return file_tronevents_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *RawData) GetTransaction() string {
+	if x != nil {
+		return x.Transaction
+	}
+	return ""
+}
+
+func (x *RawData) GetTransactionInfo() string {
+	if x != nil {
+		return x.TransactionInfo
+	}
+	return ""
+}
+
+// BlockStats is the payload of the block stats stream.
+type BlockStats struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	BlockNumber      int64                  `protobuf:"varint,1,opt,name=block_number,json=blockNumber,proto3" json:"block_number,omitempty"`
+	BlockHash        string                 `protobuf:"bytes,2,opt,name=block_hash,json=blockHash,proto3" json:"block_hash,omitempty"`
+	BlockTimestampMs int64                  `protobuf:"varint,3,opt,name=block_timestamp_ms,json=blockTimestampMs,proto3" json:"block_timestamp_ms,omitempty"`
+	Producer         string                 `protobuf:"bytes,4,opt,name=producer,proto3" json:"producer,omitempty"`
+	TransactionCount int64                  `protobuf:"varint,5,opt,name=transaction_count,json=transactionCount,proto3" json:"transaction_count,omitempty"`
+	ContractTypes    map[string]int64       `protobuf:"bytes,6,rep,name=contract_types,json=contractTypes,proto3" json:"contract_types,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"varint,2,opt,name=value"`
+	SuccessCount     int64                  `protobuf:"varint,7,opt,name=success_count,json=successCount,proto3" json:"success_count,omitempty"`
+	FailureCount     int64                  `protobuf:"varint,8,opt,name=failure_count,json=failureCount,proto3" json:"failure_count,omitempty"`
+	EnergyUsed       int64                  `protobuf:"varint,9,opt,name=energy_used,json=energyUsed,proto3" json:"energy_used,omitempty"`
+	EnergyBurned     int64                  `protobuf:"varint,10,opt,name=energy_burned,json=energyBurned,proto3" json:"energy_burned,omitempty"`
+	BandwidthUsed    int64                  `protobuf:"varint,11,opt,name=bandwidth_used,json=bandwidthUsed,proto3" json:"bandwidth_used,omitempty"`
+	Trc20Transfers   int64                  `protobuf:"varint,12,opt,name=trc20_transfers,json=trc20Transfers,proto3" json:"trc20_transfers,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *BlockStats) Reset() {
+	*x = BlockStats{}
+	mi := &file_tronevents_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BlockStats) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BlockStats) ProtoMessage() {}
+
+func (x *BlockStats) ProtoReflect() protoreflect.Message {
+	mi := &file_tronevents_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BlockStats.ProtoReflect.Descriptor instead.
+func (*BlockStats) Descriptor() ([]byte, []int) {
+	return file_tronevents_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *BlockStats) GetBlockNumber() int64 {
+	if x != nil {
+		return x.BlockNumber
+	}
+	return 0
+}
+
+func (x *BlockStats) GetBlockHash() string {
+	if x != nil {
+		return x.BlockHash
+	}
+	return ""
+}
+
+func (x *BlockStats) GetBlockTimestampMs() int64 {
+	if x != nil {
+		return x.BlockTimestampMs
+	}
+	return 0
+}
+
+func (x *BlockStats) GetProducer() string {
+	if x != nil {
+		return x.Producer
+	}
+	return ""
+}
+
+func (x *BlockStats) GetTransactionCount() int64 {
+	if x != nil {
+		return x.TransactionCount
+	}
+	return 0
+}
+
+func (x *BlockStats) GetContractTypes() map[string]int64 {
+	if x != nil {
+		return x.ContractTypes
+	}
+	return nil
+}
+
+func (x *BlockStats) GetSuccessCount() int64 {
+	if x != nil {
+		return x.SuccessCount
+	}
+	return 0
+}
+
+func (x *BlockStats) GetFailureCount() int64 {
+	if x != nil {
+		return x.FailureCount
+	}
+	return 0
+}
+
+func (x *BlockStats) GetEnergyUsed() int64 {
+	if x != nil {
+		return x.EnergyUsed
+	}
+	return 0
+}
+
+func (x *BlockStats) GetEnergyBurned() int64 {
+	if x != nil {
+		return x.EnergyBurned
+	}
+	return 0
+}
+
+func (x *BlockStats) GetBandwidthUsed() int64 {
+	if x != nil {
+		return x.BandwidthUsed
+	}
+	return 0
+}
+
+func (x *BlockStats) GetTrc20Transfers() int64 {
+	if x != nil {
+		return x.Trc20Transfers
+	}
+	return 0
+}
+
+var File_tronevents_proto protoreflect.FileDescriptor
+
+const file_tronevents_proto_rawDesc = "" +
+	"\n" +
+	"\x10tronevents.proto\x12\rtronevents.v1\x1a\x1cgoogle/protobuf/struct.proto\"\xa6\x04\n" +
+	"\vTransaction\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x123\n" +
+	"\bcontract\x18\x02 \x01(\v2\x17.tronevents.v1.ContractR\bcontract\x12(\n" +
+	"\x03ret\x18\x03 \x01(\v2\x16.tronevents.v1.RetInfoR\x03ret\x12!\n" +
+	"\ftimestamp_ms\x18\x04 \x01(\x03R\vtimestampMs\x12!\n" +
+	"\fblock_number\x18\x05 \x01(\x03R\vblockNumber\x12,\n" +
+	"\x12block_timestamp_ms\x18\x06 \x01(\x03R\x10blockTimestampMs\x12#\n" +
+	"\rexpiration_ms\x18\a \x01(\x03R\fexpirationMs\x120\n" +
+	"\areceipt\x18\b \x01(\v2\x16.tronevents.v1.ReceiptR\areceipt\x12*\n" +
+	"\x04logs\x18\t \x03(\v2\x16.tronevents.v1.LogInfoR\x04logs\x12\x18\n" +
+	"\asigners\x18\n" +
+	" \x03(\tR\asigners\x12(\n" +
+	"\x03raw\x18\v \x01(\v2\x16.tronevents.v1.RawDataR\x03raw\x12W\n" +
+	"\x15internal_transactions\x18\f \x03(\v2\".tronevents.v1.InternalTransactionR\x14internalTransactions\x12\x14\n" +
+	"\x05index\x18\r \x01(\x05R\x05index\"\x90\x01\n" +
+	"\bContract\x12\x12\n" +
+	"\x04type\x18\x01 \x01(\tR\x04type\x125\n" +
+	"\tparameter\x18\x04 \x01(\v2\x17.google.protobuf.StructR\tparameter\x12#\n" +
+	"\rpermission_id\x18\x03 \x01(\x05R\fpermissionIdJ\x04\b\x02\x10\x03R\x0eparameter_json\",\n" +
+	"\aRetInfo\x12!\n" +
+	"\fcontract_ret\x18\x01 \x01(\tR\vcontractRet\"\xf7\x01\n" +
+	"\aReceipt\x12!\n" +
+	"\fenergy_usage\x18\x01 \x01(\x03R\venergyUsage\x12\x1d\n" +
+	"\n" +
+	"energy_fee\x18\x02 \x01(\x03R\tenergyFee\x12.\n" +
+	"\x13origin_energy_usage\x18\x03 \x01(\x03R\x11originEnergyUsage\x12,\n" +
+	"\x12energy_usage_total\x18\x04 \x01(\x03R\x10energyUsageTotal\x12\x1b\n" +
+	"\tnet_usage\x18\x05 \x01(\x03R\bnetUsage\x12\x17\n" +
+	"\anet_fee\x18\x06 \x01(\x03R\x06netFee\x12\x16\n" +
+	"\x06result\x18\a \x01(\tR\x06result\"\x96\x01\n" +
+	"\x13InternalTransaction\x12%\n" +
+	"\x0ecaller_address\x18\x01 \x01(\tR\rcallerAddress\x12\x1d\n" +
+	"\n" +
+	"to_address\x18\x02 \x01(\tR\ttoAddress\x12\x1d\n" +
+	"\n" +
+	"call_value\x18\x03 \x01(\x03R\tcallValue\x12\x1a\n" +
+	"\brejected\x18\x04 \x01(\bR\brejected\"\xc3\x01\n" +
+	"\aLogInfo\x12\x1d\n" +
+	"\n" +
+	"event_name\x18\x01 \x01(\tR\teventName\x12\x1c\n" +
+	"\tsignature\x18\x02 \x01(\tR\tsignature\x121\n" +
+	"\x06inputs\x18\x03 \x03(\v2\x19.tronevents.v1.EventInputR\x06inputs\x12\x18\n" +
+	"\aaddress\x18\x04 \x01(\tR\aaddress\x12.\n" +
+	"\x05token\x18\x05 \x01(\v2\x18.tronevents.v1.TokenInfoR\x05token\"\x8e\x01\n" +
+	"\n" +
+	"EventInput\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x12\n" +
+	"\x04type\x18\x02 \x01(\tR\x04type\x12,\n" +
+	"\x05value\x18\x04 \x01(\v2\x16.google.protobuf.ValueR\x05value\x12\x18\n" +
+	"\aindexed\x18\x05 \x01(\bR\aindexedJ\x04\b\x03\x10\x04R\n" +
+	"value_json\"k\n" +
+	"\tTokenInfo\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x16\n" +
+	"\x06symbol\x18\x02 \x01(\tR\x06symbol\x12\x1a\n" +
+	"\bdecimals\x18\x03 \x01(\x05R\bdecimals\x12\x16\n" +
+	"\x06amount\x18\x04 \x01(\tR\x06amount\"\xfb\x02\n" +
+	"\bLogEvent\x12\x12\n" +
+	"\x04txid\x18\x01 \x01(\tR\x04txid\x12!\n" +
+	"\fblock_number\x18\x02 \x01(\x03R\vblockNumber\x12,\n" +
+	"\x12block_timestamp_ms\x18\x03 \x01(\x03R\x10blockTimestampMs\x12\x19\n" +
+	"\btx_index\x18\x04 \x01(\x05R\atxIndex\x12\x1b\n" +
+	"\tlog_index\x18\x05 \x01(\x05R\blogIndex\x12\x18\n" +
+	"\aaddress\x18\x06 \x01(\tR\aaddress\x12\x1d\n" +
+	"\n" +
+	"event_name\x18\a \x01(\tR\teventName\x12\x1c\n" +
+	"\tsignature\x18\b \x01(\tR\tsignature\x121\n" +
+	"\x06inputs\x18\t \x03(\v2\x19.tronevents.v1.EventInputR\x06inputs\x12.\n" +
+	"\x05token\x18\n" +
+	" \x01(\v2\x18.tronevents.v1.TokenInfoR\x05token\x12\x18\n" +
+	"\asuccess\x18\v \x01(\bR\asuccess\"V\n" +
+	"\aRawData\x12 \n" +
+	"\vtransaction\x18\x01 \x01(\tR\vtransaction\x12)\n" +
+	"\x10transaction_info\x18\x02 \x01(\tR\x0ftransactionInfo\"\xbc\x04\n" +
+	"\n" +
+	"BlockStats\x12!\n" +
+	"\fblock_number\x18\x01 \x01(\x03R\vblockNumber\x12\x1d\n" +
+	"\n" +
+	"block_hash\x18\x02 \x01(\tR\tblockHash\x12,\n" +
+	"\x12block_timestamp_ms\x18\x03 \x01(\x03R\x10blockTimestampMs\x12\x1a\n" +
+	"\bproducer\x18\x04 \x01(\tR\bproducer\x12+\n" +
+	"\x11transaction_count\x18\x05 \x01(\x03R\x10transactionCount\x12S\n" +
+	"\x0econtract_types\x18\x06 \x03(\v2,.tronevents.v1.BlockStats.ContractTypesEntryR\rcontractTypes\x12#\n" +
+	"\rsuccess_count\x18\a \x01(\x03R\fsuccessCount\x12#\n" +
+	"\rfailure_count\x18\b \x01(\x03R\ffailureCount\x12\x1f\n" +
+	"\venergy_used\x18\t \x01(\x03R\n" +
+	"energyUsed\x12#\n" +
+	"\renergy_burned\x18\n" +
+	" \x01(\x03R\fenergyBurned\x12%\n" +
+	"\x0ebandwidth_used\x18\v \x01(\x03R\rbandwidthUsed\x12'\n" +
+	"\x0ftrc20_transfers\x18\f \x01(\x03R\x0etrc20Transfers\x1a@\n" +
+	"\x12ContractTypesEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\x03R\x05value:\x028\x01B.Z,github.com/sunbankio/tronevents/pkg/codec/pbb\x06proto3"
+
+var (
+	file_tronevents_proto_rawDescOnce sync.Once
+	file_tronevents_proto_rawDescData []byte
+)
+
+func file_tronevents_proto_rawDescGZIP() []byte {
+	file_tronevents_proto_rawDescOnce.Do(func() {
+		file_tronevents_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_tronevents_proto_rawDesc), len(file_tronevents_proto_rawDesc)))
+	})
+	return file_tronevents_proto_rawDescData
+}
+
+var file_tronevents_proto_msgTypes = make([]protoimpl.MessageInfo, 12)
+var file_tronevents_proto_goTypes = []any{
+	(*Transaction)(nil),         // 0: tronevents.v1.Transaction
+	(*Contract)(nil),            // 1: tronevents.v1.Contract
+	(*RetInfo)(nil),             // 2: tronevents.v1.RetInfo
+	(*Receipt)(nil),             // 3: tronevents.v1.Receipt
+	(*InternalTransaction)(nil), // 4: tronevents.v1.InternalTransaction
+	(*LogInfo)(nil),             // 5: tronevents.v1.LogInfo
+	(*EventInput)(nil),          // 6: tronevents.v1.EventInput
+	(*TokenInfo)(nil),           // 7: tronevents.v1.TokenInfo
+	(*LogEvent)(nil),            // 8: tronevents.v1.LogEvent
+	(*RawData)(nil),             // 9: tronevents.v1.RawData
+	(*BlockStats)(nil),          // 10: tronevents.v1.BlockStats
+	nil,                         // 11: tronevents.v1.BlockStats.ContractTypesEntry
+	(*structpb.Struct)(nil),     // 12: google.protobuf.Struct
+	(*structpb.Value)(nil),      // 13: google.protobuf.Value
+}
+var file_tronevents_proto_depIdxs = []int32{
+	1,  // 0: tronevents.v1.Transaction.contract:type_name -> tronevents.v1.Contract
+	2,  // 1: tronevents.v1.Transaction.ret:type_name -> tronevents.v1.RetInfo
+	3,  // 2: tronevents.v1.Transaction.receipt:type_name -> tronevents.v1.Receipt
+	5,  // 3: tronevents.v1.Transaction.logs:type_name -> tronevents.v1.LogInfo
+	9,  // 4: tronevents.v1.Transaction.raw:type_name -> tronevents.v1.RawData
+	4,  // 5: tronevents.v1.Transaction.internal_transactions:type_name -> tronevents.v1.InternalTransaction
+	12, // 6: tronevents.v1.Contract.parameter:type_name -> google.protobuf.Struct
+	6,  // 7: tronevents.v1.LogInfo.inputs:type_name -> tronevents.v1.EventInput
+	7,  // 8: tronevents.v1.LogInfo.token:type_name -> tronevents.v1.TokenInfo
+	13, // 9: tronevents.v1.EventInput.value:type_name -> google.protobuf.Value
+	6,  // 10: tronevents.v1.LogEvent.inputs:type_name -> tronevents.v1.EventInput
+	7,  // 11: tronevents.v1.LogEvent.token:type_name -> tronevents.v1.TokenInfo
+	11, // 12: tronevents.v1.BlockStats.contract_types:type_name -> tronevents.v1.BlockStats.ContractTypesEntry
+	13, // [13:13] is the sub-list for method output_type
+	13, // [13:13] is the sub-list for method input_type
+	13, // [13:13] is the sub-list for extension type_name
+	13, // [13:13] is the sub-list for extension extendee
+	0,  // [0:13] is the sub-list for field type_name
+}
+
+func init() { file_tronevents_proto_init() }
+func file_tronevents_proto_init() {
+	if File_tronevents_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_tronevents_proto_rawDesc), len(file_tronevents_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   12,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_tronevents_proto_goTypes,
+		DependencyIndexes: file_tronevents_proto_depIdxs,
+		MessageInfos:      file_tronevents_proto_msgTypes,
+	}.Build()
+	File_tronevents_proto = out.File
+	file_tronevents_proto_goTypes = nil
+	file_tronevents_proto_depIdxs = nil
+}
@@ -0,0 +1,366 @@
+package codec
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sunbankio/tronevents/pkg/codec/pb"
+	"github.com/sunbankio/tronevents/pkg/models"
+	"github.com/sunbankio/tronevents/pkg/scanner"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// maxExactInteger is the largest integer a double holds exactly; larger integers are strings in Values.
+const maxExactInteger = 1 << 53
+
+// protoMarshal marshals the messages deterministically, so a payload doesn't depend on map order.
+var protoMarshal = proto.MarshalOptions{Deterministic: true}
+
+// The functions below convert the payloads to and from the messages of tronevents.proto.
+
+func transactionMessage(tx *models.SafeTransaction) (proto.Message, error) {
+	m := &pb.Transaction{
+		Id:               tx.ID,
+		TimestampMs:      timeMillis(tx.Timestamp.Time),
+		BlockNumber:      tx.BlockNumber,
+		BlockTimestampMs: timeMillis(tx.BlockTimestamp.Time),
+		ExpirationMs:     timeMillis(tx.Expiration.Time),
+		Signers:          tx.Signers,
+		Index:            int32(tx.Index),
+	}
+	if tx.Contract != nil {
+		contract, err := contractMessage(tx.Contract)
+		if err != nil {
+			return nil, err
+		}
+		m.Contract = contract
+	}
+	if tx.Ret != nil {
+		m.Ret = &pb.RetInfo{ContractRet: tx.Ret.ContractRet}
+	}
+	if tx.Receipt != nil {
+		m.Receipt = receiptMessage(tx.Receipt)
+	}
+	for i := range tx.Logs {
+		log, err := logInfoMessage(&tx.Logs[i])
+		if err != nil {
+			return nil, err
+		}
+		m.Logs = append(m.Logs, log)
+	}
+	if tx.Raw != nil {
+		m.Raw = rawDataMessage(tx.Raw)
+	}
+	for _, internal := range tx.InternalTransactions {
+		m.InternalTransactions = append(m.InternalTransactions, &pb.InternalTransaction{
+			CallerAddress: internal.CallerAddress,
+			ToAddress:     internal.ToAddress,
+			CallValue:     internal.CallValue,
+			Rejected:      internal.Rejected,
+		})
+	}
+	return m, nil
+}
+
+func contractMessage(contract *scanner.Contract) (*pb.Contract, error) {
+	m := &pb.Contract{Type: contract.Type, PermissionId: int32(contract.PermissionID)}
+	if contract.Parameter != nil {
+		parameter, err := protoValue(contract.Parameter)
+		if err != nil {
+			return nil, fmt.Errorf("encode contract parameter: %w", err)
+		}
+		if m.Parameter = parameter.GetStructValue(); m.Parameter == nil {
+			return nil, fmt.Errorf("encode contract parameter: %T is not an object", contract.Parameter)
+		}
+	}
+	return m, nil
+}
+
+func receiptMessage(receipt *scanner.Receipt) *pb.Receipt {
+	return &pb.Receipt{
+		EnergyUsage:       receipt.EnergyUsage,
+		EnergyFee:         receipt.EnergyFee,
+		OriginEnergyUsage: receipt.OriginEnergyUsage,
+		EnergyUsageTotal:  receipt.EnergyUsageTotal,
+		NetUsage:          receipt.NetUsage,
+		NetFee:            receipt.NetFee,
+		Result:            receipt.Result,
+	}
+}
+
+func logInfoMessage(log *scanner.LogInfo) (*pb.LogInfo, error) {
+	inputs, err := eventInputMessages(log.Inputs)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.LogInfo{
+		EventName: log.EventName,
+		Signature: log.Signature,
+		Inputs:    inputs,
+		Address:   log.Address,
+		Token:     tokenInfoMessage(log.Token),
+	}, nil
+}
+
+func logEventMessage(event *models.LogEvent) (proto.Message, error) {
+	inputs, err := eventInputMessages(event.Inputs)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.LogEvent{
+		Txid:             event.TxID,
+		BlockNumber:      event.BlockNumber,
+		BlockTimestampMs: timeMillis(event.BlockTimestamp.Time),
+		TxIndex:          int32(event.TxIndex),
+		LogIndex:         int32(event.LogIndex),
+		Address:          event.Address,
+		EventName:        event.EventName,
+		Signature:        event.Signature,
+		Inputs:           inputs,
+		Token:            tokenInfoMessage(event.Token),
+		Success:          event.Success,
+	}, nil
+}
+
+func eventInputMessages(inputs []scanner.EventInput) ([]*pb.EventInput, error) {
+	var messages []*pb.EventInput
+	for _, input := range inputs {
+		value, err := protoValue(input.Value)
+		if err != nil {
+			return nil, fmt.Errorf("encode event input %s: %w", input.Name, err)
+		}
+		messages = append(messages, &pb.EventInput{Name: input.Name, Type: input.Type, Value: value, Indexed: input.Indexed})
+	}
+	return messages, nil
+}
+
+func tokenInfoMessage(token *scanner.TokenInfo) *pb.TokenInfo {
+	if token == nil {
+		return nil
+	}
+	return &pb.TokenInfo{
+		Name:     token.Name,
+		Symbol:   token.Symbol,
+		Decimals: int32(token.Decimals),
+		Amount:   token.Amount,
+	}
+}
+
+func rawDataMessage(raw *scanner.RawData) *pb.RawData {
+	return &pb.RawData{Transaction: raw.Transaction, TransactionInfo: raw.TransactionInfo}
+}
+
+func blockStatsMessage(stats *scanner.BlockStats) *pb.BlockStats {
+	return &pb.BlockStats{
+		BlockNumber:      stats.BlockNumber,
+		BlockHash:        stats.BlockHash,
+		BlockTimestampMs: timeMillis(stats.BlockTimestamp),
+		Producer:         stats.Producer,
+		TransactionCount: int64(stats.TransactionCount),
+		ContractTypes:    stats.ContractTypes,
+		SuccessCount:     stats.SuccessCount,
+		FailureCount:     stats.FailureCount,
+		EnergyUsed:       stats.EnergyUsed,
+		EnergyBurned:     stats.EnergyBurned,
+		BandwidthUsed:    stats.BandwidthUsed,
+		Trc20Transfers:   stats.TRC20Transfers,
+	}
+}
+
+func parseTransaction(b []byte, tx *models.SafeTransaction) error {
+	var m pb.Transaction
+	if err := proto.Unmarshal(b, &m); err != nil {
+		return err
+	}
+	tx.ID = m.Id
+	if m.Contract != nil {
+		tx.Contract = &scanner.Contract{
+			Type:         m.Contract.Type,
+			PermissionID: int(m.Contract.PermissionId),
+		}
+		if m.Contract.Parameter != nil {
+			tx.Contract.Parameter = m.Contract.Parameter.AsMap()
+		}
+	}
+	if m.Ret != nil {
+		tx.Ret = &scanner.RetInfo{ContractRet: m.Ret.ContractRet}
+	}
+	tx.Timestamp.Time = millisTime(m.TimestampMs)
+	tx.BlockNumber = m.BlockNumber
+	tx.BlockTimestamp.Time = millisTime(m.BlockTimestampMs)
+	tx.Expiration.Time = millisTime(m.ExpirationMs)
+	if r := m.Receipt; r != nil {
+		tx.Receipt = &scanner.Receipt{
+			EnergyUsage:       r.EnergyUsage,
+			EnergyFee:         r.EnergyFee,
+			OriginEnergyUsage: r.OriginEnergyUsage,
+			EnergyUsageTotal:  r.EnergyUsageTotal,
+			NetUsage:          r.NetUsage,
+			NetFee:            r.NetFee,
+			Result:            r.Result,
+		}
+	}
+	for _, log := range m.Logs {
+		tx.Logs = append(tx.Logs, scanner.LogInfo{
+			EventName: log.EventName,
+			Signature: log.Signature,
+			Inputs:    eventInputs(log.Inputs),
+			Address:   log.Address,
+			Token:     tokenInfo(log.Token),
+		})
+	}
+	tx.Signers = m.Signers
+	if m.Raw != nil {
+		tx.Raw = rawData(m.Raw)
+	}
+	for _, internal := range m.InternalTransactions {
+		tx.InternalTransactions = append(tx.InternalTransactions, scanner.InternalTransaction{
+			CallerAddress: internal.CallerAddress,
+			ToAddress:     internal.ToAddress,
+			CallValue:     internal.CallValue,
+			Rejected:      internal.Rejected,
+		})
+	}
+	tx.Index = int(m.Index)
+	return nil
+}
+
+func parseLogEvent(b []byte, event *models.LogEvent) error {
+	var m pb.LogEvent
+	if err := proto.Unmarshal(b, &m); err != nil {
+		return err
+	}
+	event.TxID = m.Txid
+	event.BlockNumber = m.BlockNumber
+	event.BlockTimestamp.Time = millisTime(m.BlockTimestampMs)
+	event.TxIndex = int(m.TxIndex)
+	event.LogIndex = int(m.LogIndex)
+	event.Address = m.Address
+	event.EventName = m.EventName
+	event.Signature = m.Signature
+	event.Inputs = eventInputs(m.Inputs)
+	event.Token = tokenInfo(m.Token)
+	event.Success = m.Success
+	return nil
+}
+
+func eventInputs(messages []*pb.EventInput) []scanner.EventInput {
+	var inputs []scanner.EventInput
+	for _, m := range messages {
+		input := scanner.EventInput{Name: m.Name, Type: m.Type, Indexed: m.Indexed}
+		if m.Value != nil {
+			input.Value = m.Value.AsInterface()
+		}
+		inputs = append(inputs, input)
+	}
+	return inputs
+}
+
+func tokenInfo(m *pb.TokenInfo) *scanner.TokenInfo {
+	if m == nil {
+		return nil
+	}
+	return &scanner.TokenInfo{
+		Name:     m.Name,
+		Symbol:   m.Symbol,
+		Decimals: int(m.Decimals),
+		Amount:   m.Amount,
+	}
+}
+
+func rawData(m *pb.RawData) *scanner.RawData {
+	return &scanner.RawData{Transaction: m.Transaction, TransactionInfo: m.TransactionInfo}
+}
+
+func parseRawData(b []byte, raw *scanner.RawData) error {
+	var m pb.RawData
+	if err := proto.Unmarshal(b, &m); err != nil {
+		return err
+	}
+	*raw = *rawData(&m)
+	return nil
+}
+
+func parseBlockStats(b []byte, stats *scanner.BlockStats) error {
+	var m pb.BlockStats
+	if err := proto.Unmarshal(b, &m); err != nil {
+		return err
+	}
+	stats.BlockNumber = m.BlockNumber
+	stats.BlockHash = m.BlockHash
+	stats.BlockTimestamp = millisTime(m.BlockTimestampMs)
+	stats.Producer = m.Producer
+	stats.TransactionCount = int(m.TransactionCount)
+	stats.ContractTypes = m.ContractTypes
+	if stats.ContractTypes == nil {
+		stats.ContractTypes = make(map[string]int64)
+	}
+	stats.SuccessCount = m.SuccessCount
+	stats.FailureCount = m.FailureCount
+	stats.EnergyUsed = m.EnergyUsed
+	stats.EnergyBurned = m.EnergyBurned
+	stats.BandwidthUsed = m.BandwidthUsed
+	stats.TRC20Transfers = m.Trc20Transfers
+	return nil
+}
+
+// protoValue converts v to a google.protobuf.Value with the structure of its JSON document.
+func protoValue(v interface{}) (*structpb.Value, error) {
+	document, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(document))
+	decoder.UseNumber()
+	var generic interface{}
+	if err := decoder.Decode(&generic); err != nil {
+		return nil, err
+	}
+	return structpb.NewValue(structValue(generic))
+}
+
+// structValue replaces the JSON numbers of a document with doubles, or with strings for the integers
+// a double can't hold exactly.
+func structValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for key, value := range v {
+			v[key] = structValue(value)
+		}
+	case []interface{}:
+		for i, value := range v {
+			v[i] = structValue(value)
+		}
+	case json.Number:
+		if i, err := v.Int64(); err == nil && i >= -maxExactInteger && i <= maxExactInteger {
+			return float64(i)
+		}
+		if strings.ContainsAny(v.String(), ".eE") {
+			if f, err := v.Float64(); err == nil {
+				return f
+			}
+		}
+		return v.String()
+	}
+	return v
+}
+
+// timeMillis returns the Unix milliseconds of a time, or 0 for zero and out of range times.
+func timeMillis(t time.Time) int64 {
+	if t.IsZero() || t.Year() < 0 || t.Year() > 9999 {
+		return 0
+	}
+	return t.UnixMilli()
+}
+
+// millisTime is the inverse of timeMillis.
+func millisTime(ms int64) time.Time {
+	if ms == 0 {
+		return time.Time{}
+	}
+	return time.UnixMilli(ms).UTC()
+}
@@ -0,0 +1,270 @@
+package codec
+
+import (
+	"math/big"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/sunbankio/tronevents/pkg/codec/pb"
+	"github.com/sunbankio/tronevents/pkg/models"
+	"github.com/sunbankio/tronevents/pkg/scanner"
+	"google.golang.org/protobuf/proto"
+)
+
+var (
+	protobuf     = Encoding{Format: FormatProtobuf}
+	protobufZstd = Encoding{Format: FormatProtobuf, Compression: CompressionZstd}
+)
+
+// uint256 is a TRC20 amount beyond the integers a double holds exactly.
+const uint256 = "115792089237316195423570985008687907853269984665640564039457584007913129639935"
+
+func testTransaction() *models.SafeTransaction {
+	amount, _ := new(big.Int).SetString(uint256, 10)
+	blockTime := time.UnixMilli(1700000000000).UTC()
+	tx := models.ConvertTransaction(scanner.Transaction{
+		ID:    "a1b2",
+		Index: 7,
+		Contract: &scanner.Contract{
+			Type: "TriggerSmartContract",
+			Parameter: scanner.TriggerSmartContract{
+				OwnerAddress:    "TOwner",
+				ContractAddress: "TContract",
+				Data:            "a9059cbb",
+				CallValue:       1 << 60,
+			},
+			PermissionID: 2,
+		},
+		Ret:            &scanner.RetInfo{ContractRet: "SUCCESS"},
+		Timestamp:      blockTime.Add(-time.Second),
+		BlockNumber:    70000000,
+		BlockTimestamp: blockTime,
+		Expiration:     blockTime.Add(time.Minute),
+		Receipt: &scanner.Receipt{
+			EnergyUsage:      100,
+			EnergyFee:        13000000,
+			EnergyUsageTotal: 31895,
+			NetUsage:         345,
+			Result:           "SUCCESS",
+		},
+		Logs: []scanner.LogInfo{{
+			EventName: "Transfer",
+			Signature: "Transfer(address,address,uint256)",
+			Address:   "TContract",
+			Inputs: []scanner.EventInput{
+				{Name: "from", Type: "address", Value: "TOwner", Indexed: true},
+				{Name: "to", Type: "address", Value: "TTo", Indexed: true},
+				{Name: "value", Type: "uint256", Value: amount},
+			},
+			Token: &scanner.TokenInfo{Name: "Tether USD", Symbol: "USDT", Decimals: 6, Amount: "1.5"},
+		}},
+		Signers: []string{"TOwner", "TCosigner"},
+		Raw:     &scanner.RawData{Transaction: "CgI=", TransactionInfo: "EgI="},
+		InternalTransactions: []scanner.InternalTransaction{
+			{CallerAddress: "TContract", ToAddress: "TTo", CallValue: 5, Rejected: true},
+		},
+	})
+	return &tx
+}
+
+func TestProtobufTransactionRoundTrip(t *testing.T) {
+	for _, encoding := range []Encoding{protobuf, protobufZstd} {
+		t.Run(encoding.String(), func(t *testing.T) {
+			tx := testTransaction()
+			payload, err := encoding.EncodeTransaction(tx)
+			if err != nil {
+				t.Fatalf("EncodeTransaction: %v", err)
+			}
+			decoded, err := encoding.DecodeTransaction(payload)
+			if err != nil {
+				t.Fatalf("DecodeTransaction: %v", err)
+			}
+
+			// Parameters and input values decode with the structure of their JSON documents
+			tx.Contract.Parameter = map[string]interface{}{
+				"owner_address":    "TOwner",
+				"contract_address": "TContract",
+				"data":             "a9059cbb",
+				"call_value":       "1152921504606846976",
+			}
+			tx.Logs[0].Inputs[2].Value = uint256
+			if !reflect.DeepEqual(decoded, tx) {
+				t.Errorf("decoded transaction = %+v, want %+v", decoded, tx)
+			}
+		})
+	}
+}
+
+func TestProtobufTransactionSchema(t *testing.T) {
+	payload, err := protobuf.EncodeTransaction(testTransaction())
+	if err != nil {
+		t.Fatalf("EncodeTransaction: %v", err)
+	}
+	var m pb.Transaction
+	if err := proto.Unmarshal(payload, &m); err != nil {
+		t.Fatalf("unmarshal generated Transaction: %v", err)
+	}
+
+	if m.Id != "a1b2" || m.Index != 7 || m.BlockNumber != 70000000 || m.BlockTimestampMs != 1700000000000 {
+		t.Errorf("transaction fields = %v", &m)
+	}
+	parameter := m.GetContract().GetParameter().GetFields()
+	if got := parameter["owner_address"].GetStringValue(); got != "TOwner" {
+		t.Errorf("parameter owner_address = %q, want TOwner", got)
+	}
+	if got := parameter["call_value"].GetStringValue(); got != "1152921504606846976" {
+		t.Errorf("parameter call_value = %q, want the integer as a string", got)
+	}
+	if m.GetContract().GetPermissionId() != 2 {
+		t.Errorf("permission_id = %d, want 2", m.GetContract().GetPermissionId())
+	}
+
+	inputs := m.GetLogs()[0].GetInputs()
+	if got := inputs[0].GetValue().GetStringValue(); got != "TOwner" {
+		t.Errorf("input from = %q, want TOwner", got)
+	}
+	if got := inputs[2].GetValue().GetStringValue(); got != uint256 {
+		t.Errorf("input value = %q, want %s", got, uint256)
+	}
+	if got := m.GetLogs()[0].GetToken().GetSymbol(); got != "USDT" {
+		t.Errorf("token symbol = %q, want USDT", got)
+	}
+	if internal := m.GetInternalTransactions()[0]; !internal.Rejected || internal.CallValue != 5 {
+		t.Errorf("internal transaction = %v", internal)
+	}
+}
+
+func TestProtobufSmallIntegersAreNumbers(t *testing.T) {
+	tx := &models.SafeTransaction{
+		ID: "c3d4",
+		Contract: &scanner.Contract{
+			Type:      "TransferContract",
+			Parameter: scanner.TransferContract{OwnerAddress: "TOwner", ToAddress: "TTo", Amount: 1500000000},
+		},
+		Logs: []scanner.LogInfo{{Inputs: []scanner.EventInput{
+			{Name: "value", Type: "uint256", Value: big.NewInt(-(1 << 53))},
+			{Name: "flag", Type: "bool", Value: true},
+			{Name: "ratio", Type: "fixed", Value: 0.25},
+		}}},
+	}
+	payload, err := protobuf.EncodeTransaction(tx)
+	if err != nil {
+		t.Fatalf("EncodeTransaction: %v", err)
+	}
+	var m pb.Transaction
+	if err := proto.Unmarshal(payload, &m); err != nil {
+		t.Fatalf("unmarshal generated Transaction: %v", err)
+	}
+
+	if got := m.GetContract().GetParameter().GetFields()["amount"].GetNumberValue(); got != 1500000000 {
+		t.Errorf("parameter amount = %v, want 1500000000", got)
+	}
+	inputs := m.GetLogs()[0].GetInputs()
+	if got := inputs[0].GetValue().GetNumberValue(); got != -(1 << 53) {
+		t.Errorf("input value = %v, want %d", got, -(1 << 53))
+	}
+	if !inputs[1].GetValue().GetBoolValue() {
+		t.Errorf("input flag = %v, want true", inputs[1].GetValue())
+	}
+	if got := inputs[2].GetValue().GetNumberValue(); got != 0.25 {
+		t.Errorf("input ratio = %v, want 0.25", got)
+	}
+}
+
+func TestProtobufRejectsNonObjectParameter(t *testing.T) {
+	tx := &models.SafeTransaction{ID: "e5f6", Contract: &scanner.Contract{Type: "Custom", Parameter: []string{"a"}}}
+	if _, err := protobuf.EncodeTransaction(tx); err == nil {
+		t.Fatal("EncodeTransaction succeeded with a parameter that is not an object")
+	}
+}
+
+func TestProtobufLogEventRoundTrip(t *testing.T) {
+	tx := testTransaction()
+	event := models.LogEvent{
+		TxID:           tx.ID,
+		BlockNumber:    tx.BlockNumber,
+		BlockTimestamp: tx.BlockTimestamp,
+		TxIndex:        tx.Index,
+		LogIndex:       0,
+		Address:        tx.Logs[0].Address,
+		EventName:      tx.Logs[0].EventName,
+		Signature:      tx.Logs[0].Signature,
+		Inputs:         tx.Logs[0].Inputs,
+		Token:          tx.Logs[0].Token,
+		Success:        true,
+	}
+	payload, err := protobuf.EncodeLog(&event)
+	if err != nil {
+		t.Fatalf("EncodeLog: %v", err)
+	}
+
+	var m pb.LogEvent
+	if err := proto.Unmarshal(payload, &m); err != nil {
+		t.Fatalf("unmarshal generated LogEvent: %v", err)
+	}
+	if m.Txid != "a1b2" || m.TxIndex != 7 || !m.Success || m.GetInputs()[2].GetValue().GetStringValue() != uint256 {
+		t.Errorf("generated LogEvent = %v", &m)
+	}
+
+	decoded, err := protobuf.DecodeLog(payload)
+	if err != nil {
+		t.Fatalf("DecodeLog: %v", err)
+	}
+	event.Inputs = []scanner.EventInput{event.Inputs[0], event.Inputs[1], {Name: "value", Type: "uint256", Value: uint256}}
+	if !reflect.DeepEqual(decoded, &event) {
+		t.Errorf("decoded log = %+v, want %+v", decoded, &event)
+	}
+}
+
+func TestProtobufBlockStatsRoundTrip(t *testing.T) {
+	stats := &scanner.BlockStats{
+		BlockNumber:      70000000,
+		BlockHash:        "00000000042c1d80",
+		BlockTimestamp:   time.UnixMilli(1700000000000).UTC(),
+		Producer:         "TProducer",
+		TransactionCount: 3,
+		ContractTypes:    map[string]int64{"TransferContract": 2, "TriggerSmartContract": 1},
+		SuccessCount:     2,
+		FailureCount:     1,
+		EnergyUsed:       31895,
+		EnergyBurned:     13000000,
+		BandwidthUsed:    690,
+		TRC20Transfers:   1,
+	}
+	payload, err := protobuf.EncodeBlockStats(stats)
+	if err != nil {
+		t.Fatalf("EncodeBlockStats: %v", err)
+	}
+
+	var m pb.BlockStats
+	if err := proto.Unmarshal(payload, &m); err != nil {
+		t.Fatalf("unmarshal generated BlockStats: %v", err)
+	}
+	if m.ContractTypes["TransferContract"] != 2 || m.Trc20Transfers != 1 {
+		t.Errorf("generated BlockStats = %v", &m)
+	}
+
+	decoded, err := protobuf.DecodeBlockStats(payload)
+	if err != nil {
+		t.Fatalf("DecodeBlockStats: %v", err)
+	}
+	if !reflect.DeepEqual(decoded, stats) {
+		t.Errorf("decoded block stats = %+v, want %+v", decoded, stats)
+	}
+}
+
+func TestProtobufRawRoundTrip(t *testing.T) {
+	raw := &scanner.RawData{Transaction: "CgI=", TransactionInfo: "EgI="}
+	payload, err := protobufZstd.EncodeRaw(raw)
+	if err != nil {
+		t.Fatalf("EncodeRaw: %v", err)
+	}
+	decoded, err := protobufZstd.DecodeRaw(payload)
+	if err != nil {
+		t.Fatalf("DecodeRaw: %v", err)
+	}
+	if *decoded != *raw {
+		t.Errorf("decoded raw = %+v, want %+v", decoded, raw)
+	}
+}
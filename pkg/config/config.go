@@ -1,8 +1,10 @@
 package config
 
 import (
+	"fmt"
 	"os"
 
+	"github.com/sunbankio/tronevents/pkg/publisher"
 	"github.com/sunbankio/tronevents/pkg/queue"
 	"github.com/sunbankio/tronevents/pkg/redis"
 	"gopkg.in/yaml.v2"
@@ -14,14 +16,28 @@ type TronConfig struct {
 	Timeout     int    `yaml:"timeout"`
 	PoolSize    int    `yaml:"pool_size"`
 	MaxPoolSize int    `yaml:"max_pool_size"`
+	// TokenMetadata enables enrichment of TRC20 transfers with token name, symbol and decimals
+	TokenMetadata bool `yaml:"token_metadata"`
+	// TokenMetadataFile is an optional YAML file with static token metadata overrides
+	TokenMetadataFile string `yaml:"token_metadata_file"`
+}
+
+// PendingConfig holds the configuration for the pending-transaction (mempool) stream.
+type PendingConfig struct {
+	Enabled      bool `yaml:"enabled"`
+	PollInterval int  `yaml:"poll_interval"` // milliseconds
 }
 
 // Config holds the configuration for the entire daemon.
 type Config struct {
-	Redis    redis.Config `yaml:"redis"`
-	Queue    queue.Config `yaml:"queue"`
-	Tron     TronConfig   `yaml:"tron"`
-	LogLevel string       `yaml:"log_level"`
+	Redis     redis.Config           `yaml:"redis"`
+	Queue     queue.Config           `yaml:"queue"`
+	Tron      TronConfig             `yaml:"tron"`
+	Publisher publisher.Config       `yaml:"publisher"`
+	Sinks     []publisher.SinkConfig `yaml:"sinks"`
+	Outbox    publisher.OutboxConfig `yaml:"outbox"`
+	Pending   PendingConfig          `yaml:"pending"`
+	LogLevel  string                 `yaml:"log_level"`
 }
 
 // LoadFromFile loads the configuration from a YAML file.
@@ -36,5 +52,33 @@ func LoadFromFile(path string) (*Config, error) {
 		return nil, err
 	}
 
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	// Derive stream names and other Redis keys from the prefix
+	prefix := config.Redis.KeyPrefix()
+	config.Publisher.Streams.ApplyPrefix(prefix)
+	config.Publisher.Watchlist.ApplyPrefix(prefix)
+	for i := range config.Sinks {
+		config.Sinks[i].ApplyPrefix(prefix)
+	}
+
 	return &config, nil
 }
+
+// Validate checks the configuration for invalid values.
+func (c *Config) Validate() error {
+	if err := c.Publisher.Validate(); err != nil {
+		return err
+	}
+	for i := range c.Sinks {
+		if err := c.Sinks[i].Validate(); err != nil {
+			return fmt.Errorf("sinks[%d]: %v", i, err)
+		}
+	}
+	if err := c.Outbox.Validate(); err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+// Package consumer decodes the entries of the tronevents Redis streams.
+//
+// Entries carry their payload in the "payload" field and its encoding in the "encoding" field,
+// e.g. "json" or "protobuf+zstd"; entries without an encoding are JSON. The envelope fields
+// describe the entry without decoding the payload, see ParseEnvelope.
+package consumer
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/sunbankio/tronevents/pkg/codec"
+	"github.com/sunbankio/tronevents/pkg/models"
+	"github.com/sunbankio/tronevents/pkg/scanner"
+)
+
+// SupportedVersion is the highest envelope schema version decoded by this package.
+const SupportedVersion = 1
+
+var (
+	// ErrNoPayload is returned for entries without a payload, such as block markers.
+	ErrNoPayload = errors.New("entry has no payload")
+	// ErrUnsupportedVersion is returned for entries of a newer schema version than SupportedVersion.
+	ErrUnsupportedVersion = errors.New("unsupported entry schema version")
+)
+
+// Envelope holds the metadata fields of a stream entry.
+type Envelope struct {
+	Version  int    // schema version, 0 for entries published before the envelope
+	Type     string // e.g. "transaction", "block", "block_start"
+	Network  string
+	Block    int64
+	Index    int // position of the transaction in its block
+	LogIndex int // position of the log in its transaction, on the logs stream
+	TxID     string
+	Producer string // instance of the publishing daemon
+}
+
+// ParseEnvelope returns the envelope of an entry. It fails with ErrUnsupportedVersion for entries
+// that this package cannot decode, so consumers can stop rather than misread them.
+func ParseEnvelope(msg redis.XMessage) (Envelope, error) {
+	field := func(name string) string {
+		value, _ := msg.Values[name].(string)
+		return value
+	}
+	integer := func(name string) (int64, error) {
+		value := field(name)
+		if value == "" {
+			return 0, nil
+		}
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("entry %s: invalid %s field %q", msg.ID, name, value)
+		}
+		return n, nil
+	}
+
+	e := Envelope{Type: field("type"), Network: field("network"), TxID: field("txid"), Producer: field("producer")}
+	version, err := integer("v")
+	if err != nil {
+		return Envelope{}, err
+	}
+	if version > SupportedVersion {
+		return Envelope{}, fmt.Errorf("entry %s: %w %d", msg.ID, ErrUnsupportedVersion, version)
+	}
+	e.Version = int(version)
+	if e.Block, err = integer("block"); err != nil {
+		return Envelope{}, err
+	}
+	index, err := integer("index")
+	if err != nil {
+		return Envelope{}, err
+	}
+	e.Index = int(index)
+	logIndex, err := integer("log_index")
+	if err != nil {
+		return Envelope{}, err
+	}
+	e.LogIndex = int(logIndex)
+	return e, nil
+}
+
+// Encoding returns the payload encoding of an entry.
+func Encoding(msg redis.XMessage) (codec.Encoding, error) {
+	encoding, _ := msg.Values["encoding"].(string)
+	return codec.Parse(encoding)
+}
+
+// DecodeTransaction decodes the transaction of an events, routed or pending stream entry.
+func DecodeTransaction(msg redis.XMessage) (*models.SafeTransaction, error) {
+	encoding, payload, err := entryPayload(msg)
+	if err != nil {
+		return nil, err
+	}
+	return encoding.DecodeTransaction(payload)
+}
+
+// DecodeLog decodes the log of a logs stream entry.
+func DecodeLog(msg redis.XMessage) (*models.LogEvent, error) {
+	encoding, payload, err := entryPayload(msg)
+	if err != nil {
+		return nil, err
+	}
+	return encoding.DecodeLog(payload)
+}
+
+// DecodeBlockStats decodes the statistics of a block stats stream entry.
+func DecodeBlockStats(msg redis.XMessage) (*scanner.BlockStats, error) {
+	encoding, payload, err := entryPayload(msg)
+	if err != nil {
+		return nil, err
+	}
+	return encoding.DecodeBlockStats(payload)
+}
+
+// DecodeRaw decodes the raw protobufs of a raw stream entry.
+func DecodeRaw(msg redis.XMessage) (*scanner.RawData, error) {
+	encoding, payload, err := entryPayload(msg)
+	if err != nil {
+		return nil, err
+	}
+	return encoding.DecodeRaw(payload)
+}
+
+func entryPayload(msg redis.XMessage) (codec.Encoding, []byte, error) {
+	if _, err := ParseEnvelope(msg); err != nil {
+		return codec.Encoding{}, nil, err
+	}
+	payload, ok := msg.Values["payload"].(string)
+	if !ok {
+		return codec.Encoding{}, nil, ErrNoPayload
+	}
+	encoding, err := Encoding(msg)
+	if err != nil {
+		return codec.Encoding{}, nil, fmt.Errorf("entry %s: %w", msg.ID, err)
+	}
+	return encoding, []byte(payload), nil
+}
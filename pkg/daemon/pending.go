@@ -0,0 +1,136 @@
+package daemon
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	tronScanner "github.com/sunbankio/tronevents/pkg/scanner"
+)
+
+const (
+	defaultPendingPollInterval = 1000 * time.Millisecond
+	// pendingTTL is how long a pending transaction is tracked while waiting to be included in a block
+	pendingTTL = 10 * time.Minute
+)
+
+// pendingTracker remembers which pending transactions have been published.
+type pendingTracker struct {
+	mu   sync.Mutex
+	seen map[string]time.Time
+}
+
+func newPendingTracker() *pendingTracker {
+	return &pendingTracker{
+		seen: make(map[string]time.Time),
+	}
+}
+
+// isSeen reports whether the transaction has already been published as pending.
+func (t *pendingTracker) isSeen(txID string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	_, ok := t.seen[txID]
+	return ok
+}
+
+// markSeen records the transaction as published.
+func (t *pendingTracker) markSeen(txID string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.seen[txID] = time.Now()
+}
+
+// takeIncluded returns the tracked transactions among txs and stops tracking them.
+func (t *pendingTracker) takeIncluded(txs []tronScanner.Transaction) []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var included []string
+	for i := range txs {
+		if _, ok := t.seen[txs[i].ID]; ok {
+			included = append(included, txs[i].ID)
+			delete(t.seen, txs[i].ID)
+		}
+	}
+	return included
+}
+
+// prune drops transactions that have been tracked for longer than maxAge.
+func (t *pendingTracker) prune(maxAge time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	cutoff := time.Now().Add(-maxAge)
+	for txID, seenAt := range t.seen {
+		if seenAt.Before(cutoff) {
+			delete(t.seen, txID)
+		}
+	}
+}
+
+// runPendingLoop polls the node's pending pool and publishes newly seen transactions.
+func (s *Service) runPendingLoop(ctx context.Context) {
+	interval := time.Duration(s.config.Pending.PollInterval) * time.Millisecond
+	if interval <= 0 {
+		interval = defaultPendingPollInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.pollPending(ctx)
+		}
+	}
+}
+
+// pollPending fetches the pending pool once and publishes transactions not seen before.
+func (s *Service) pollPending(ctx context.Context) {
+	txIDs, err := s.tronScanner.PendingTransactionIDs(ctx)
+	if err != nil {
+		s.logger.Errorf("[PENDING] Failed to list pending transactions: %v", err)
+		return
+	}
+
+	transactions := make([]*tronScanner.Transaction, 0)
+	for _, txID := range txIDs {
+		if s.pendingTracker.isSeen(txID) {
+			continue
+		}
+		tx, err := s.tronScanner.PendingTransaction(ctx, txID)
+		if err != nil {
+			// The transaction may have left the pool in the meantime
+			s.logger.Debugf("[PENDING] Failed to get pending transaction %s: %v", txID, err)
+			continue
+		}
+		transactions = append(transactions, &tx)
+	}
+
+	if err := s.pendingPublisher.PublishPending(ctx, transactions); err != nil {
+		s.logger.Errorf("[PENDING] Failed to publish %d pending transactions: %v", len(transactions), err)
+		return
+	}
+	for _, tx := range transactions {
+		s.pendingTracker.markSeen(tx.ID)
+	}
+
+	s.pendingTracker.prune(pendingTTL)
+	s.logger.Debugf("[PENDING] Published %d new pending transactions (pool size %d)", len(transactions), len(txIDs))
+}
+
+// publishIncluded emits an "included" event for pending transactions that appeared in the block.
+func (s *Service) publishIncluded(ctx context.Context, block *tronScanner.Block) {
+	if s.pendingTracker == nil {
+		return
+	}
+
+	included := s.pendingTracker.takeIncluded(block.Transactions)
+	if err := s.pendingPublisher.PublishIncluded(ctx, block.Number, included); err != nil {
+		s.logger.Errorf("[PENDING] Failed to publish %d included transactions for block %d: %v", len(included), block.Number, err)
+	}
+}
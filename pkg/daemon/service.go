@@ -30,8 +30,10 @@ type Service struct {
 	asynqClient           *asynq.Client
 	asynqServer           *asynq.Server
 	tronScanner           *tronScanner.Scanner
-	lastSyncedBlock       *storage.LastSyncedStorage
-	publisher             *publisher.EventPublisher
+	lastSyncedBlock       publisher.HeadCursor
+	publisher             publisher.Sink
+	pendingPublisher      *publisher.PendingPublisher
+	pendingTracker        *pendingTracker
 	workerManager         *worker.Manager
 	logger                *logging.Logger
 	blockProcessedStorage *storage.BlockProcessedStorage
@@ -88,17 +90,40 @@ func NewService(cfg *config.Config) *Service {
 	if err != nil {
 		panic(err)
 	}
+	tronScannerInstance.SetIncludeRaw(cfg.Publisher.IncludeRaw())
+	if cfg.Tron.TokenMetadata {
+		if err := tronScannerInstance.EnableTokenMetadata(cfg.Tron.TokenMetadataFile); err != nil {
+			panic(err)
+		}
+	}
 
 	// Use configurable Redis prefix
-	redisPrefix := cfg.Redis.Prefix
-	if redisPrefix == "" {
-		redisPrefix = "tron" // Default prefix
-	}
-	lastSyncedBlockStorage := storage.NewLastSyncedStorage(goRedisClient, redisPrefix+":last_synced_block")
+	redisPrefix := cfg.Redis.KeyPrefix()
+	var lastSyncedBlockStorage publisher.HeadCursor = storage.NewLastSyncedStorage(goRedisClient, redisPrefix+":last_synced_block")
 	blockProcessedStorage := storage.NewBlockProcessedStorage(goRedisClient, redisPrefix+":processed_blocks")
-	publisher := publisher.NewEventPublisher(goRedisClient)
+	pendingPublisher := publisher.NewPendingPublisher(goRedisClient, cfg.Publisher)
+	sink, err := publisher.NewSink(cfg.Sinks, goRedisClient, cfg.Publisher)
+	if err != nil {
+		panic(err)
+	}
+	if cfg.Outbox.Dir != "" {
+		// Buffer the head blocks the sinks fail to publish on disk, with the last synced block while
+		// Redis can't be updated
+		outboxSink, err := publisher.NewOutboxSink(sink, cfg.Outbox, blockProcessedStorage, lastSyncedBlockStorage)
+		if err != nil {
+			sink.Close()
+			panic(err)
+		}
+		sink = outboxSink
+		lastSyncedBlockStorage = outboxSink
+	}
 	workerManager := worker.NewManager(asynqServer, logging.NewLogger(cfg.LogLevel))
 
+	var pendingTrackerInstance *pendingTracker
+	if cfg.Pending.Enabled {
+		pendingTrackerInstance = newPendingTracker()
+	}
+
 	return &Service{
 		config:                cfg,
 		redisClient:           goRedisClient,
@@ -106,20 +131,31 @@ func NewService(cfg *config.Config) *Service {
 		asynqServer:           asynqServer,
 		tronScanner:           tronScannerInstance,
 		lastSyncedBlock:       lastSyncedBlockStorage,
-		publisher:             publisher,
+		publisher:             sink,
+		pendingPublisher:      pendingPublisher,
+		pendingTracker:        pendingTrackerInstance,
 		workerManager:         workerManager,
 		logger:                logging.NewLogger(cfg.LogLevel),
 		blockProcessedStorage: blockProcessedStorage,
 	}
 }
 
+// Close waits for in-flight worker tasks and closes the sinks.
+func (s *Service) Close() {
+	s.workerManager.Shutdown()
+	if err := s.publisher.Close(); err != nil {
+		s.logger.Errorf("Failed to close sinks: %v", err)
+	}
+}
+
 // RunWithContext starts the daemon service with a context for cancellation
 func (s *Service) RunWithContext(ctx context.Context) {
 	// Start cleanup process to remove entries older than 7 days, running every hour
 	s.blockProcessedStorage.StartCleanup(ctx, 1*time.Hour, 7*24*time.Hour)
 
 	// Create and register the proper task handler for the worker
-	handler := worker.NewHandler(s.tronScanner, s.publisher, s.blockProcessedStorage, s.logger)
+	// Workers publish the "included" events of the backlog blocks like the main loop does for head blocks
+	handler := worker.NewHandler(s.tronScanner, s.publisher, s.blockProcessedStorage, s.publishIncluded, s.logger)
 	mux := asynq.NewServeMux()
 	worker.RegisterHandlers(mux, handler)
 
@@ -128,14 +164,21 @@ func (s *Service) RunWithContext(ctx context.Context) {
 		s.logger.Fatal("Failed to start worker manager: ", err)
 	}
 
+	// Poll the pending pool in the background if enabled
+	if s.pendingTracker != nil {
+		go s.runPendingLoop(ctx)
+	}
+
 	// Main processing loop
 	s.runLoop(ctx)
 }
 
-// batchEnqueueBlocks enqueues multiple blocks in batch to reduce Redis operations
-func (s *Service) batchEnqueueBlocks(blockNumbers []int64, queueName string) {
+// batchEnqueueBlocks enqueues multiple blocks in batch to reduce Redis operations.
+// It stops at the first block that fails to enqueue and reports whether every block was enqueued;
+// blocks enqueued again by a later attempt are skipped by the workers once processed.
+func (s *Service) batchEnqueueBlocks(blockNumbers []int64, queueName string) bool {
 	if len(blockNumbers) == 0 {
-		return
+		return true
 	}
 
 	// Process in smaller batches to avoid overwhelming Redis
@@ -158,11 +201,12 @@ func (s *Service) batchEnqueueBlocks(blockNumbers []int64, queueName string) {
 			task := asynq.NewTask("block:process", payload)
 			if _, err := s.asynqClient.Enqueue(task, asynq.Queue(queueName), asynq.MaxRetry(5)); err != nil {
 				s.logger.Printf("Error enqueuing block %d: %v", blockNum, err)
-			} else {
-				s.logger.Debugf("Successfully enqueued block %d to %s queue", blockNum, queueName)
+				return false
 			}
+			s.logger.Debugf("Successfully enqueued block %d to %s queue", blockNum, queueName)
 		}
 	}
+	return true
 }
 
 // runLoop contains the main processing logic
@@ -187,13 +231,14 @@ func (s *Service) runLoop(ctx context.Context) {
 		s.logger.Debugf("Loaded last_synced_block = %d", lastSyncedBlock)
 
 		// Use scanner.Scan(0) to get current block
-		s.logger.Debugf("Scanning current block with Scan(ctx, 0)")
-		returnedBlockNum, returnedBlockTime, transactions, err := s.tronScanner.Scan(ctx, 0)
+		s.logger.Debugf("Scanning current block with ScanBlock(ctx, 0)")
+		block, err := s.tronScanner.ScanBlock(ctx, 0)
 		if err != nil {
 			s.logger.Println("Error scanning current block: ", err)
 			time.Sleep(1 * time.Second)
 			continue
 		}
+		returnedBlockNum, returnedBlockTime, transactions := block.Number, block.Timestamp, block.Transactions
 		s.logger.Debugf("Scan completed - returned block: %d, transactions count: %d", returnedBlockNum, len(transactions))
 
 		// Check if lastSyncedBlock == returnedBlockNum
@@ -204,19 +249,20 @@ func (s *Service) runLoop(ctx context.Context) {
 			continue
 		}
 
-		// Publish result to Redis stream (transactions from the current block) in batch
-		// Convert slice of values to slice of pointers for batch publishing
-		transactionPointers := make([]*tronScanner.Transaction, len(transactions))
-		for i := range transactions {
-			transactionPointers[i] = &transactions[i]
-		}
-		if err := s.publisher.PublishBatch(context.Background(), transactionPointers); err != nil {
+		// Publish result to the sinks (transactions from the current block) in batch and mark the block
+		// as processed, skipping it if a worker or a previous run already processed it.
+		// On failure the block is left unprocessed and last_synced_block unchanged, so it is
+		// retried by the gap recovery of the next iterations.
+		published, err := publisher.PublishOnce(context.Background(), s.publisher, block, s.blockProcessedStorage)
+		if err != nil {
 			s.logger.Printf("Error publishing batch of transactions: %v", err)
+			time.Sleep(1 * time.Second)
+			continue
 		}
-
-		// Mark the current block as processed to prevent duplicate processing
-		if err := s.blockProcessedStorage.MarkProcessed(ctx, returnedBlockNum); err != nil {
-			s.logger.Errorf("[MAIN]    Failed to mark block %d as processed: %v", returnedBlockNum, err)
+		if published {
+			s.publishIncluded(ctx, block)
+		} else {
+			s.logger.Debugf("Block %d already processed, skipping publish", returnedBlockNum)
 		}
 
 		s.logger.Infof("[MAIN]   Block %d scanned, published %d transactions.", returnedBlockNum, len(transactions))
@@ -245,7 +291,11 @@ func (s *Service) runLoop(ctx context.Context) {
 				blockNumbers = append(blockNumbers, blockNum)
 			}
 
-			s.batchEnqueueBlocks(blockNumbers, "priority")
+			// Keep last_synced_block until the missing blocks are enqueued, so they are enqueued again
+			if !s.batchEnqueueBlocks(blockNumbers, "priority") {
+				time.Sleep(1 * time.Second)
+				continue
+			}
 			s.updateLastSyncedBlock(ctx, returnedBlockNum)
 			waitUntil(returnedBlockTime.Add(WaitInterval))
 			continue
@@ -275,7 +325,10 @@ func (s *Service) runLoop(ctx context.Context) {
 			blockNumbers = append(blockNumbers, blockNum)
 		}
 
-		s.batchEnqueueBlocks(blockNumbers, "backlog")
+		if !s.batchEnqueueBlocks(blockNumbers, "backlog") {
+			time.Sleep(1 * time.Second)
+			continue
+		}
 		s.updateLastSyncedBlock(ctx, returnedBlockNum)
 		waitUntil(returnedBlockTime.Add(WaitInterval))
 	}
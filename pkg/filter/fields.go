@@ -0,0 +1,153 @@
+package filter
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+
+	"github.com/sunbankio/tronevents/pkg/scanner"
+)
+
+// field is a transaction attribute available to expressions.
+type field struct {
+	valueKind valueKind
+	get       func(tx *scanner.Transaction) []value
+}
+
+func (f *field) kind() valueKind                        { return f.valueKind }
+func (f *field) values(tx *scanner.Transaction) []value { return f.get(tx) }
+
+// fields are the named transaction attributes; "parameter.<name>" fields are resolved by lookupField.
+var fields = map[string]*field{
+	"id": stringField(func(tx *scanner.Transaction) string { return tx.ID }),
+	"success": {valueKind: kindBool, get: func(tx *scanner.Transaction) []value {
+		return []value{{kind: kindBool, b: tx.IsSuccess()}}
+	}},
+	"contract_type": stringField(func(tx *scanner.Transaction) string {
+		if tx.Contract == nil {
+			return ""
+		}
+		return tx.Contract.Type
+	}),
+	"owner":            stringField(func(tx *scanner.Transaction) string { return tx.OwnerAddress() }),
+	"contract_address": stringField(func(tx *scanner.Transaction) string { return tx.ContractAddress() }),
+	"block_number":     numberField(func(tx *scanner.Transaction) int64 { return tx.BlockNumber }),
+	"energy_usage_total": numberField(func(tx *scanner.Transaction) int64 {
+		return receipt(tx).EnergyUsageTotal
+	}),
+	"energy_fee": numberField(func(tx *scanner.Transaction) int64 { return receipt(tx).EnergyFee }),
+	"net_usage":  numberField(func(tx *scanner.Transaction) int64 { return receipt(tx).NetUsage }),
+	"net_fee":    numberField(func(tx *scanner.Transaction) int64 { return receipt(tx).NetFee }),
+	"fee": numberField(func(tx *scanner.Transaction) int64 {
+		return receipt(tx).EnergyFee + receipt(tx).NetFee
+	}),
+	"addresses": stringsField(func(tx *scanner.Transaction) []string { return tx.Addresses() }),
+	"event_names": stringsField(func(tx *scanner.Transaction) []string {
+		names := make([]string, len(tx.Logs))
+		for i := range tx.Logs {
+			names[i] = tx.Logs[i].EventName
+		}
+		return names
+	}),
+	"log_addresses": stringsField(func(tx *scanner.Transaction) []string {
+		addresses := make([]string, len(tx.Logs))
+		for i := range tx.Logs {
+			addresses[i] = tx.Logs[i].Address
+		}
+		return addresses
+	}),
+	"transfer_amounts": {valueKind: kindNumber, get: func(tx *scanner.Transaction) []value {
+		var values []value
+		for i := range tx.Logs {
+			if !tx.Logs[i].IsTRC20Transfer() {
+				continue
+			}
+			if n, ok := new(big.Rat).SetString(fmt.Sprint(tx.Logs[i].Inputs[2].Value)); ok {
+				values = append(values, value{kind: kindNumber, n: n})
+			}
+		}
+		return values
+	}},
+}
+
+func init() {
+	fields["owner_address"] = fields["owner"]
+}
+
+// lookupField returns the named field, including "parameter.<name>" fields of the contract parameter.
+func lookupField(name string) (*field, error) {
+	if key := strings.TrimPrefix(name, "parameter."); key != name && key != "" {
+		return &field{valueKind: kindAny, get: func(tx *scanner.Transaction) []value {
+			if v, ok := toValue(tx.ParameterValue(key)); ok {
+				return []value{v}
+			}
+			return nil
+		}}, nil
+	}
+
+	if f, ok := fields[name]; ok {
+		return f, nil
+	}
+
+	names := make([]string, 0, len(fields)+1)
+	for known := range fields {
+		names = append(names, known)
+	}
+	names = append(names, "parameter.<name>")
+	sort.Strings(names)
+	return nil, fmt.Errorf("unknown field %q (available: %s)", name, strings.Join(names, ", "))
+}
+
+// toValue converts a parameter value to a typed value.
+func toValue(v interface{}) (value, bool) {
+	switch v := v.(type) {
+	case string:
+		return value{kind: kindString, s: v}, true
+	case bool:
+		return value{kind: kindBool, b: v}, true
+	case int:
+		return value{kind: kindNumber, n: new(big.Rat).SetInt64(int64(v))}, true
+	case int32:
+		return value{kind: kindNumber, n: new(big.Rat).SetInt64(int64(v))}, true
+	case int64:
+		return value{kind: kindNumber, n: new(big.Rat).SetInt64(v)}, true
+	case uint64:
+		return value{kind: kindNumber, n: new(big.Rat).SetInt(new(big.Int).SetUint64(v))}, true
+	case float64:
+		if n := new(big.Rat).SetFloat64(v); n != nil {
+			return value{kind: kindNumber, n: n}, true
+		}
+	}
+	return value{}, false
+}
+
+func stringField(get func(tx *scanner.Transaction) string) *field {
+	return &field{valueKind: kindString, get: func(tx *scanner.Transaction) []value {
+		return []value{{kind: kindString, s: get(tx)}}
+	}}
+}
+
+func stringsField(get func(tx *scanner.Transaction) []string) *field {
+	return &field{valueKind: kindString, get: func(tx *scanner.Transaction) []value {
+		strs := get(tx)
+		values := make([]value, len(strs))
+		for i, s := range strs {
+			values[i] = value{kind: kindString, s: s}
+		}
+		return values
+	}}
+}
+
+func numberField(get func(tx *scanner.Transaction) int64) *field {
+	return &field{valueKind: kindNumber, get: func(tx *scanner.Transaction) []value {
+		return []value{{kind: kindNumber, n: new(big.Rat).SetInt64(get(tx))}}
+	}}
+}
+
+func receipt(tx *scanner.Transaction) *scanner.Receipt {
+	if tx.Receipt == nil {
+		return &scanner.Receipt{}
+	}
+	return tx.Receipt
+}
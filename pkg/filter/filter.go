@@ -0,0 +1,503 @@
+// Package filter implements a small, side-effect free expression language for selecting transactions.
+//
+// An expression combines comparisons of transaction fields with literals:
+//
+//	success && contract_type == "TriggerSmartContract" && contract_address in ["TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6t"]
+//	contract_type == "TransferContract" && parameter.amount >= 1000000000
+//	!(event_names == "Transfer") || transfer_amounts > 1000000
+//	not success or contract_type contains "Freeze"
+//
+// Operators are ==, !=, <, <=, >, >=, in, contains (substring), && or the keyword and, || or the
+// keyword or, ! or the keyword not, and parentheses. The keywords are interchangeable with the symbols;
+// && binds tighter than ||, and ! applies to the nearest operand. Literals are
+// double- or single-quoted strings, decimal numbers and true/false. Fields with several values, such as
+// addresses, match if any value matches, except != which requires that no value is equal.
+package filter
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+	"unicode"
+
+	"github.com/sunbankio/tronevents/pkg/scanner"
+)
+
+// Filter is a compiled filter expression.
+type Filter struct {
+	source string
+	root   expr
+}
+
+// Compile parses and type-checks an expression.
+func Compile(expression string) (*Filter, error) {
+	tokens, err := lex(expression)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokenEOF {
+		return nil, fmt.Errorf("filter: unexpected %s at offset %d", tok, tok.pos)
+	}
+
+	return &Filter{
+		source: expression,
+		root:   root,
+	}, nil
+}
+
+// Match reports whether the transaction satisfies the expression.
+func (f *Filter) Match(tx *scanner.Transaction) bool {
+	return f.root.match(tx)
+}
+
+// String returns the source of the expression.
+func (f *Filter) String() string {
+	return f.source
+}
+
+// valueKind is the type of a literal or field.
+type valueKind int
+
+const (
+	kindAny valueKind = iota // parameter fields, typed at evaluation
+	kindBool
+	kindNumber
+	kindString
+)
+
+func (k valueKind) String() string {
+	switch k {
+	case kindBool:
+		return "bool"
+	case kindNumber:
+		return "number"
+	case kindString:
+		return "string"
+	default:
+		return "any"
+	}
+}
+
+// value is a typed value; only the field matching its kind is set.
+type value struct {
+	kind valueKind
+	b    bool
+	n    *big.Rat
+	s    string
+}
+
+// expr is a boolean expression node.
+type expr interface {
+	match(tx *scanner.Transaction) bool
+}
+
+// operand is a literal or a field, evaluated to zero or more values.
+type operand interface {
+	kind() valueKind
+	values(tx *scanner.Transaction) []value
+}
+
+type andExpr struct{ left, right expr }
+
+func (e *andExpr) match(tx *scanner.Transaction) bool { return e.left.match(tx) && e.right.match(tx) }
+
+type orExpr struct{ left, right expr }
+
+func (e *orExpr) match(tx *scanner.Transaction) bool { return e.left.match(tx) || e.right.match(tx) }
+
+type notExpr struct{ inner expr }
+
+func (e *notExpr) match(tx *scanner.Transaction) bool { return !e.inner.match(tx) }
+
+// truthExpr is a bool operand used as an expression, e.g. "success".
+type truthExpr struct{ operand operand }
+
+func (e *truthExpr) match(tx *scanner.Transaction) bool {
+	for _, v := range e.operand.values(tx) {
+		if v.kind == kindBool && v.b {
+			return true
+		}
+	}
+	return false
+}
+
+type compareExpr struct {
+	op          string
+	left, right operand
+}
+
+func (e *compareExpr) match(tx *scanner.Transaction) bool {
+	left, right := e.left.values(tx), e.right.values(tx)
+
+	if e.op == "!=" {
+		return !anyPair(left, right, "==")
+	}
+	return anyPair(left, right, e.op)
+}
+
+type inExpr struct {
+	left operand
+	set  []value
+}
+
+func (e *inExpr) match(tx *scanner.Transaction) bool {
+	return anyPair(e.left.values(tx), e.set, "==")
+}
+
+// anyPair reports whether any pair of left and right values satisfies the comparison.
+func anyPair(left, right []value, op string) bool {
+	for _, l := range left {
+		for _, r := range right {
+			if compare(l, r, op) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// compare applies a comparison to two values, coercing untyped parameter values to the other side's kind.
+func compare(l, r value, op string) bool {
+	l, r = coerce(l, r.kind), coerce(r, l.kind)
+	if l.kind != r.kind {
+		return false
+	}
+
+	var cmp int
+	switch l.kind {
+	case kindNumber:
+		cmp = l.n.Cmp(r.n)
+	case kindString:
+		if op == "contains" {
+			return strings.Contains(l.s, r.s)
+		}
+		cmp = strings.Compare(l.s, r.s)
+	case kindBool:
+		if l.b != r.b {
+			cmp = 1
+		}
+	}
+
+	switch op {
+	case "==":
+		return cmp == 0
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	case ">=":
+		return cmp >= 0
+	}
+	return false
+}
+
+// coerce converts a numeric string to a number when compared with a number, e.g. uint256 event values.
+func coerce(v value, kind valueKind) value {
+	if v.kind == kindString && kind == kindNumber {
+		if n, ok := new(big.Rat).SetString(v.s); ok {
+			return value{kind: kindNumber, n: n}
+		}
+	}
+	return v
+}
+
+// literal is a constant operand.
+type literal struct{ v value }
+
+func (l *literal) kind() valueKind                     { return l.v.kind }
+func (l *literal) values(*scanner.Transaction) []value { return []value{l.v} }
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdent
+	tokenString
+	tokenNumber
+	tokenOp
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+func (t token) String() string {
+	if t.kind == tokenEOF {
+		return "end of expression"
+	}
+	return fmt.Sprintf("%q", t.text)
+}
+
+// lex splits an expression into tokens.
+func lex(src string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(src); {
+		c := rune(src[i])
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case c == '"' || c == '\'':
+			j := i + 1
+			var b strings.Builder
+			for ; j < len(src) && rune(src[j]) != c; j++ {
+				if src[j] == '\\' && j+1 < len(src) {
+					j++
+				}
+				b.WriteByte(src[j])
+			}
+			if j >= len(src) {
+				return nil, fmt.Errorf("filter: unterminated string at offset %d", i)
+			}
+			tokens = append(tokens, token{tokenString, b.String(), i})
+			i = j + 1
+		case unicode.IsDigit(c) || (c == '-' && i+1 < len(src) && unicode.IsDigit(rune(src[i+1]))):
+			j := i + 1
+			for j < len(src) && (unicode.IsDigit(rune(src[j])) || src[j] == '.') {
+				j++
+			}
+			tokens = append(tokens, token{tokenNumber, src[i:j], i})
+			i = j
+		case unicode.IsLetter(c) || c == '_':
+			j := i + 1
+			for j < len(src) && (unicode.IsLetter(rune(src[j])) || unicode.IsDigit(rune(src[j])) || src[j] == '_' || src[j] == '.') {
+				j++
+			}
+			tokens = append(tokens, token{tokenIdent, src[i:j], i})
+			i = j
+		default:
+			op := ""
+			for _, candidate := range []string{"==", "!=", "<=", ">=", "&&", "||", "<", ">", "!", "(", ")", "[", "]", ","} {
+				if strings.HasPrefix(src[i:], candidate) {
+					op = candidate
+					break
+				}
+			}
+			if op == "" {
+				return nil, fmt.Errorf("filter: unexpected character %q at offset %d", c, i)
+			}
+			tokens = append(tokens, token{tokenOp, op, i})
+			i += len(op)
+		}
+	}
+	return append(tokens, token{kind: tokenEOF, pos: len(src)}), nil
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokenEOF {
+		p.pos++
+	}
+	return tok
+}
+
+// accept consumes the next token if it is one of the given operators or keywords.
+func (p *parser) accept(texts ...string) bool {
+	tok := p.peek()
+	if tok.kind != tokenOp && tok.kind != tokenIdent {
+		return false
+	}
+	for _, text := range texts {
+		if tok.text == text {
+			p.pos++
+			return true
+		}
+	}
+	return false
+}
+
+func (p *parser) expect(text string) error {
+	if !p.accept(text) {
+		tok := p.peek()
+		return fmt.Errorf("filter: expected %q, found %s at offset %d", text, tok, tok.pos)
+	}
+	return nil
+}
+
+func (p *parser) parseOr() (expr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("||", "or") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &orExpr{left, right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (expr, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("&&", "and") {
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &andExpr{left, right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseUnary() (expr, error) {
+	if p.accept("!", "not") {
+		inner, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &notExpr{inner}, nil
+	}
+	if p.accept("(") {
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+		return inner, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *parser) parseComparison() (expr, error) {
+	start := p.peek()
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+
+	tok := p.peek()
+	switch {
+	case tok.kind == tokenOp && isComparison(tok.text), tok.kind == tokenIdent && tok.text == "contains":
+		p.next()
+		right, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		if err := checkComparison(tok, left.kind(), right.kind()); err != nil {
+			return nil, err
+		}
+		return &compareExpr{op: tok.text, left: left, right: right}, nil
+
+	case tok.kind == tokenIdent && tok.text == "in":
+		p.next()
+		set, err := p.parseList()
+		if err != nil {
+			return nil, err
+		}
+		for _, v := range set {
+			if err := checkComparison(tok, left.kind(), v.kind); err != nil {
+				return nil, err
+			}
+		}
+		return &inExpr{left: left, set: set}, nil
+
+	default:
+		if left.kind() != kindBool && left.kind() != kindAny {
+			return nil, fmt.Errorf("filter: %s is a %s, expected a comparison at offset %d", start, left.kind(), tok.pos)
+		}
+		return &truthExpr{left}, nil
+	}
+}
+
+func (p *parser) parseList() ([]value, error) {
+	if err := p.expect("["); err != nil {
+		return nil, err
+	}
+	var set []value
+	for !p.accept("]") {
+		if len(set) > 0 {
+			if err := p.expect(","); err != nil {
+				return nil, err
+			}
+		}
+		tok := p.peek()
+		operand, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		lit, ok := operand.(*literal)
+		if !ok {
+			return nil, fmt.Errorf("filter: lists may only contain literals, found %s at offset %d", tok, tok.pos)
+		}
+		set = append(set, lit.v)
+	}
+	return set, nil
+}
+
+func (p *parser) parseOperand() (operand, error) {
+	tok := p.next()
+	switch tok.kind {
+	case tokenString:
+		return &literal{value{kind: kindString, s: tok.text}}, nil
+	case tokenNumber:
+		n, ok := new(big.Rat).SetString(tok.text)
+		if !ok {
+			return nil, fmt.Errorf("filter: invalid number %q at offset %d", tok.text, tok.pos)
+		}
+		return &literal{value{kind: kindNumber, n: n}}, nil
+	case tokenIdent:
+		switch tok.text {
+		case "true", "false":
+			return &literal{value{kind: kindBool, b: tok.text == "true"}}, nil
+		}
+		field, err := lookupField(tok.text)
+		if err != nil {
+			return nil, fmt.Errorf("filter: %v at offset %d", err, tok.pos)
+		}
+		return field, nil
+	default:
+		return nil, fmt.Errorf("filter: expected a field or literal, found %s at offset %d", tok, tok.pos)
+	}
+}
+
+func isComparison(op string) bool {
+	switch op {
+	case "==", "!=", "<", "<=", ">", ">=":
+		return true
+	}
+	return false
+}
+
+// checkComparison rejects comparisons between incompatible kinds, ordering of non-numbers and
+// substrings of non-strings.
+func checkComparison(op token, left, right valueKind) error {
+	if left != kindAny && right != kindAny && left != right {
+		return fmt.Errorf("filter: cannot compare %s with %s at offset %d", left, right, op.pos)
+	}
+	switch op.text {
+	case "contains":
+		if (left != kindAny && left != kindString) || (right != kindAny && right != kindString) {
+			return fmt.Errorf("filter: contains requires strings at offset %d", op.pos)
+		}
+	case "<", "<=", ">", ">=":
+		if left == kindBool || right == kindBool || left == kindString || right == kindString {
+			return fmt.Errorf("filter: %s requires numbers at offset %d", op.text, op.pos)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,226 @@
+package filter
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/sunbankio/tronevents/pkg/scanner"
+)
+
+const (
+	usdt     = "TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6t"
+	owner    = "TOwnerAddress1111111111111111111"
+	receiver = "TReceiverAddress11111111111111111"
+)
+
+// usdtTransfer is a successful TRC20 transfer of 2500 USDT.
+func usdtTransfer() *scanner.Transaction {
+	value, _ := new(big.Int).SetString("2500000000", 10)
+	return &scanner.Transaction{
+		ID:          "a1b2",
+		BlockNumber: 70000000,
+		Contract: &scanner.Contract{
+			Type: "TriggerSmartContract",
+			Parameter: scanner.TriggerSmartContract{
+				OwnerAddress:    owner,
+				ContractAddress: usdt,
+				Data:            "a9059cbb",
+			},
+		},
+		Receipt: &scanner.Receipt{
+			EnergyUsageTotal: 31895,
+			EnergyFee:        13000000,
+			NetUsage:         345,
+			NetFee:           0,
+			Result:           "SUCCESS",
+		},
+		Logs: []scanner.LogInfo{{
+			EventName: "Transfer",
+			Address:   usdt,
+			Inputs: []scanner.EventInput{
+				{Name: "from", Type: "address", Value: owner},
+				{Name: "to", Type: "address", Value: receiver},
+				{Name: "value", Type: "uint256", Value: value},
+			},
+		}},
+	}
+}
+
+// trxTransfer is a plain transfer of 1500 TRX, with its parameter as a map like custom parsers return.
+func trxTransfer() *scanner.Transaction {
+	return &scanner.Transaction{
+		ID:          "c3d4",
+		BlockNumber: 70000001,
+		Contract: &scanner.Contract{
+			Type: "TransferContract",
+			Parameter: map[string]interface{}{
+				"owner_address": owner,
+				"to_address":    receiver,
+				"amount":        int64(1500000000),
+			},
+		},
+	}
+}
+
+// revertedCall is a failed smart contract call without logs.
+func revertedCall() *scanner.Transaction {
+	return &scanner.Transaction{
+		ID:          "e5f6",
+		BlockNumber: 70000002,
+		Contract: &scanner.Contract{
+			Type: "TriggerSmartContract",
+			Parameter: &scanner.TriggerSmartContract{
+				OwnerAddress:    receiver,
+				ContractAddress: usdt,
+				CallValue:       10,
+			},
+		},
+		Receipt: &scanner.Receipt{Result: "REVERT", EnergyFee: 500},
+	}
+}
+
+func TestMatch(t *testing.T) {
+	type want struct{ usdt, trx, reverted bool }
+	tests := []struct {
+		expression string
+		want       want
+	}{
+		// Comparisons
+		{`contract_type == "TransferContract"`, want{false, true, false}},
+		{`contract_type != "TransferContract"`, want{true, false, true}},
+		{`block_number < 70000001`, want{true, false, false}},
+		{`block_number <= 70000001`, want{true, true, false}},
+		{`block_number > 70000001`, want{false, false, true}},
+		{`block_number >= 70000001`, want{false, true, true}},
+		{`fee == 13000000`, want{true, false, false}},
+		{`energy_usage_total > 30000`, want{true, false, false}},
+		{`net_usage == 345 && net_fee == 0`, want{true, false, false}},
+		{`energy_fee >= 500`, want{true, false, true}},
+		{`id == 'c3d4'`, want{false, true, false}},
+		{`owner == "` + owner + `"`, want{true, true, false}},
+		{`owner_address == "` + receiver + `"`, want{false, false, true}},
+		{`contract_address == "` + usdt + `"`, want{true, false, true}},
+		{`success`, want{true, true, false}},
+		{`success == false`, want{false, false, true}},
+		{`true`, want{true, true, true}},
+
+		// in
+		{`contract_type in ["TransferContract", "FreezeBalanceV2Contract"]`, want{false, true, false}},
+		{`block_number in [70000000, 70000002]`, want{true, false, true}},
+		{`contract_type in []`, want{false, false, false}},
+
+		// contains
+		{`contract_type contains "Smart"`, want{true, false, true}},
+		{`contract_type contains "transfer"`, want{false, false, false}},
+		{`event_names contains "Trans"`, want{true, false, false}},
+		{`id contains ""`, want{true, true, true}},
+
+		// Multi-valued fields
+		{`addresses == "` + receiver + `"`, want{true, true, true}},
+		{`addresses != "` + owner + `"`, want{false, false, true}},
+		{`log_addresses == "` + usdt + `"`, want{true, false, false}},
+		{`event_names == "Transfer"`, want{true, false, false}},
+		{`transfer_amounts > 1000000000`, want{true, false, false}},
+		{`transfer_amounts == 2500000000`, want{true, false, false}},
+		{`transfer_amounts < 1`, want{false, false, false}},
+
+		// parameter.<name>, from contract structs, pointers and maps
+		{`parameter.amount >= 1000000000`, want{false, true, false}},
+		{`parameter.amount == "1500000000"`, want{false, true, false}},
+		{`parameter.call_value > 0`, want{false, false, true}},
+		{`parameter.data == "a9059cbb"`, want{true, false, false}},
+		{`parameter.to_address == "` + receiver + `"`, want{false, true, false}},
+		{`parameter.missing == 1`, want{false, false, false}},
+		{`parameter.owner_address contains "TOwner"`, want{true, true, false}},
+
+		// Boolean operators and precedence
+		{`!success`, want{false, false, true}},
+		{`not success`, want{false, false, true}},
+		{`!!success`, want{true, true, false}},
+		{`success and contract_type == "TransferContract"`, want{false, true, false}},
+		{`!success or contract_type == "TransferContract"`, want{false, true, true}},
+		{`not success or contract_type == "TransferContract"`, want{false, true, true}},
+		{`not (success and contract_type == "TriggerSmartContract")`, want{false, true, true}},
+		{`not not success`, want{true, true, false}},
+		// The keywords mix with the symbols and share their precedence: false or (true and false)
+		{`contract_type == "TransferContract" or success and block_number > 70000001`, want{false, true, false}},
+		{`success && contract_type == "TransferContract" or not success`, want{false, true, true}},
+		{`(contract_type == "TransferContract" || success) and block_number > 70000000`, want{false, true, false}},
+		// && binds tighter than ||: false || (true && false)
+		{`contract_type == "TransferContract" || success && block_number > 70000001`, want{false, true, false}},
+		{`(contract_type == "TransferContract" || success) && block_number > 70000000`, want{false, true, false}},
+		// ! applies to the nearest operand only
+		{`!success && contract_type == "TriggerSmartContract"`, want{false, false, true}},
+		{`!(success && contract_type == "TriggerSmartContract")`, want{false, true, true}},
+		{`success && (contract_type == "TriggerSmartContract" && contract_address in ["` + usdt + `"] || contract_type == "TransferContract" && parameter.amount >= 1000000000)`, want{true, true, false}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expression, func(t *testing.T) {
+			f, err := Compile(tt.expression)
+			if err != nil {
+				t.Fatalf("Compile: %v", err)
+			}
+			got := want{f.Match(usdtTransfer()), f.Match(trxTransfer()), f.Match(revertedCall())}
+			if got != tt.want {
+				t.Errorf("Match (usdt, trx, reverted) = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	tests := []struct {
+		expression string
+		err        string
+	}{
+		{`amount > 1`, `unknown field "amount"`},
+		{`parameter. == 1`, `unknown field "parameter."`},
+		{`contract_typ == "TransferContract"`, `unknown field "contract_typ"`},
+		{`contract_type == 1`, `cannot compare string with number`},
+		{`block_number == "70000000"`, `cannot compare number with string`},
+		{`success == "true"`, `cannot compare bool with string`},
+		{`contract_type > "A"`, `> requires numbers`},
+		{`success < true`, `< requires numbers`},
+		{`block_number contains 7`, `contains requires strings`},
+		{`contract_type in [1, 2]`, `cannot compare string with number`},
+		{`contract_type in ["A", owner]`, `lists may only contain literals`},
+		{`contract_type in "A"`, `expected "["`},
+		{`contract_type`, `is a string, expected a comparison`},
+		{`block_number`, `is a number, expected a comparison`},
+		{`success &&`, `expected a field or literal`},
+		{`success and`, `expected a field or literal`},
+		{`success or or success`, `unknown field "or"`},
+		{`not`, `expected a field or literal`},
+		{`(success`, `expected ")"`},
+		{`success)`, `unexpected ")"`},
+		{`success success`, `unexpected "success"`},
+		{`contract_type == "unterminated`, `unterminated string`},
+		{`success # comment`, `unexpected character '#'`},
+		{``, `expected a field or literal`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expression, func(t *testing.T) {
+			_, err := Compile(tt.expression)
+			if err == nil {
+				t.Fatalf("Compile succeeded, want error containing %q", tt.err)
+			}
+			if !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("Compile error = %q, want it to contain %q", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestString(t *testing.T) {
+	const expression = `success && fee > 0`
+	f, err := Compile(expression)
+	if err != nil {
+		t.Fatalf("Compile: %v", err)
+	}
+	if f.String() != expression {
+		t.Errorf("String() = %q, want %q", f.String(), expression)
+	}
+}
@@ -33,6 +33,7 @@ func (t *SafeTime) UnmarshalJSON(data []byte) error {
 // SafeTransaction wraps scanner.Transaction with safe time handling
 type SafeTransaction struct {
 	ID             string                `json:"id"`
+	Index          int                   `json:"index"` // Position of the transaction in its block
 	Contract       *tronScanner.Contract `json:"contract,omitempty"`
 	Ret            *tronScanner.RetInfo  `json:"ret,omitempty"`
 	Timestamp      SafeTime              `json:"timestamp"`
@@ -42,12 +43,16 @@ type SafeTransaction struct {
 	Receipt        *tronScanner.Receipt  `json:"receipt,omitempty"`
 	Logs           []tronScanner.LogInfo `json:"logs,omitempty"`
 	Signers        []string              `json:"signers,omitempty"` // All signers for the transaction
+	Raw            *tronScanner.RawData  `json:"raw,omitempty"`
+
+	InternalTransactions []tronScanner.InternalTransaction `json:"internal_transactions,omitempty"`
 }
 
 // ConvertTransaction converts a scanner.Transaction to a SafeTransaction
 func ConvertTransaction(tx tronScanner.Transaction) SafeTransaction {
 	return SafeTransaction{
 		ID:             tx.ID,
+		Index:          tx.Index,
 		Contract:       tx.Contract,
 		Ret:            tx.Ret,
 		Timestamp:      SafeTime{tx.Timestamp},
@@ -57,5 +62,41 @@ func ConvertTransaction(tx tronScanner.Transaction) SafeTransaction {
 		Receipt:        tx.Receipt,
 		Logs:           tx.Logs,
 		Signers:        tx.Signers,
+		Raw:            tx.Raw,
+
+		InternalTransactions: tx.InternalTransactions,
+	}
+}
+
+// LogEvent is a single decoded log of a transaction, published to the logs stream
+type LogEvent struct {
+	TxID           string                   `json:"txid"`
+	BlockNumber    int64                    `json:"block_number"`
+	BlockTimestamp SafeTime                 `json:"block_timestamp"`
+	TxIndex        int                      `json:"tx_index"`          // Position of the transaction in its block
+	LogIndex       int                      `json:"log_index"`         // Position of the log in its transaction
+	Address        string                   `json:"address,omitempty"` // Contract emitting the event
+	EventName      string                   `json:"event_name"`
+	Signature      string                   `json:"signature"`
+	Inputs         []tronScanner.EventInput `json:"inputs,omitempty"`
+	Token          *tronScanner.TokenInfo   `json:"token,omitempty"`
+	Success        bool                     `json:"success"` // Whether the parent transaction succeeded
+}
+
+// ConvertLog converts the logIndex-th log of a transaction to a LogEvent
+func ConvertLog(tx *tronScanner.Transaction, logIndex int) LogEvent {
+	log := &tx.Logs[logIndex]
+	return LogEvent{
+		TxID:           tx.ID,
+		BlockNumber:    tx.BlockNumber,
+		BlockTimestamp: SafeTime{tx.BlockTimestamp},
+		TxIndex:        tx.Index,
+		LogIndex:       logIndex,
+		Address:        log.Address,
+		EventName:      log.EventName,
+		Signature:      log.Signature,
+		Inputs:         log.Inputs,
+		Token:          log.Token,
+		Success:        tx.IsSuccess(),
 	}
-}
\ No newline at end of file
+}
@@ -0,0 +1,552 @@
+package publisher
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/sunbankio/tronevents/pkg/scanner"
+	"github.com/xitongsys/parquet-go/parquet"
+	"github.com/xitongsys/parquet-go/writer"
+)
+
+const (
+	// SinkTypeArchive writes blocks to compressed JSONL (and optionally Parquet) files.
+	SinkTypeArchive = "archive"
+
+	// Archive compressions
+	ArchiveCompressionGzip = "gzip"
+	ArchiveCompressionZstd = "zstd"
+	ArchiveCompressionNone = "none"
+
+	defaultArchiveBlockRange      = 100000
+	defaultParquetBatchMB         = 64
+	defaultParquetBatchInterval   = 3600
+	archiveManifestName           = "manifest.json"
+	archiveParquetSpoolName       = "parquet.spool"
+	archiveParquetBatchFileFormat = "batch-%d.parquet"
+)
+
+// ArchiveConfig holds the configuration of the archive sink.
+type ArchiveConfig struct {
+	Directory   string `yaml:"directory"`
+	Compression string `yaml:"compression"` // gzip (default), zstd or none
+	BlockRange  int64  `yaml:"block_range"` // blocks per partition (default: 100000)
+	Parquet     bool   `yaml:"parquet"`     // also write the blocks to Parquet files in batches
+	// ParquetBatchMB is the size of the spooled rows at which a Parquet file is written (default 64).
+	ParquetBatchMB int `yaml:"parquet_batch_mb"`
+	// ParquetBatchInterval is the number of seconds after which the spooled rows are written to a
+	// Parquet file even if the batch is smaller (default 3600).
+	ParquetBatchInterval int `yaml:"parquet_batch_interval"`
+}
+
+// Validate checks the archive configuration for invalid values.
+func (c *ArchiveConfig) Validate() error {
+	if c.Directory == "" {
+		return fmt.Errorf("archive: directory is required")
+	}
+	switch c.Compression {
+	case "", ArchiveCompressionGzip, ArchiveCompressionZstd, ArchiveCompressionNone:
+	default:
+		return fmt.Errorf("archive: unknown compression %q", c.Compression)
+	}
+	if c.BlockRange < 0 {
+		return fmt.Errorf("archive: block_range must not be negative")
+	}
+	if c.ParquetBatchMB < 0 || c.ParquetBatchInterval < 0 {
+		return fmt.Errorf("archive: parquet_batch_mb and parquet_batch_interval must not be negative")
+	}
+	return nil
+}
+
+// BlockRange is an inclusive range of block numbers.
+type BlockRange struct {
+	Start int64 `json:"start"`
+	End   int64 `json:"end"`
+}
+
+// ArchiveManifest records the block ranges covered by the archive, overall and per file.
+type ArchiveManifest struct {
+	UpdatedAt time.Time               `json:"updated_at"`
+	Ranges    []BlockRange            `json:"ranges"`
+	Files     map[string][]BlockRange `json:"files"`
+	// Sizes holds the length of the appended files, JSONL files and Parquet spools, as of the last
+	// recorded block. Anything past it was written for a block the manifest doesn't include yet, and
+	// is truncated before the next append.
+	Sizes map[string]int64 `json:"sizes,omitempty"`
+}
+
+// archiveParquetRow is the Parquet schema of an archived transaction.
+type archiveParquetRow struct {
+	TxID             string `parquet:"name=txid, type=BYTE_ARRAY, convertedtype=UTF8"`
+	BlockNumber      int64  `parquet:"name=block_number, type=INT64"`
+	BlockTimestamp   int64  `parquet:"name=block_timestamp, type=INT64, convertedtype=TIMESTAMP_MILLIS"`
+	TxIndex          int32  `parquet:"name=tx_index, type=INT32"`
+	ContractType     string `parquet:"name=contract_type, type=BYTE_ARRAY, convertedtype=UTF8"`
+	OwnerAddress     string `parquet:"name=owner_address, type=BYTE_ARRAY, convertedtype=UTF8"`
+	Success          bool   `parquet:"name=success, type=BOOLEAN"`
+	EnergyUsageTotal int64  `parquet:"name=energy_usage_total, type=INT64"`
+	EnergyFee        int64  `parquet:"name=energy_fee, type=INT64"`
+	NetUsage         int64  `parquet:"name=net_usage, type=INT64"`
+	NetFee           int64  `parquet:"name=net_fee, type=INT64"`
+	Payload          string `parquet:"name=payload, type=BYTE_ARRAY, convertedtype=UTF8"` // the JSONL record
+}
+
+// ArchiveSink writes the transactions of each block as JSON lines into files partitioned by block date
+// and block range, e.g. date=2025-01-02/blocks=70000000-70099999.jsonl.gz.
+// Every block is appended as its own compressed member, so blocks can arrive in any order, and blocks
+// already recorded in the manifest are skipped, so retries don't duplicate records. An append the
+// manifest doesn't record, because the daemon stopped before updating it, is truncated before the
+// block is written again.
+//
+// With Parquet enabled, the rows of each partition are spooled to disk and written to a Parquet file
+// once the spool reaches the batch size or age.
+type ArchiveSink struct {
+	config   ArchiveConfig
+	mu       sync.Mutex
+	manifest ArchiveManifest
+	encoder  *zstd.Encoder
+	// spools holds the time each Parquet spool of the manifest was started, or found at startup
+	spools map[string]time.Time
+	// cloudEventsSource switches the JSONL lines to CloudEvents when set
+	cloudEventsSource string
+}
+
+// NewArchiveSink creates a new ArchiveSink, loading the existing manifest from the directory.
+func NewArchiveSink(cfg ArchiveConfig) (*ArchiveSink, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	if cfg.Compression == "" {
+		cfg.Compression = ArchiveCompressionGzip
+	}
+	if cfg.BlockRange == 0 {
+		cfg.BlockRange = defaultArchiveBlockRange
+	}
+	if err := os.MkdirAll(cfg.Directory, 0o755); err != nil {
+		return nil, err
+	}
+
+	s := &ArchiveSink{
+		config:   cfg,
+		manifest: ArchiveManifest{Files: make(map[string][]BlockRange)},
+	}
+
+	data, err := os.ReadFile(filepath.Join(cfg.Directory, archiveManifestName))
+	if err == nil {
+		if err := json.Unmarshal(data, &s.manifest); err != nil {
+			return nil, fmt.Errorf("archive: invalid manifest: %w", err)
+		}
+		if s.manifest.Files == nil {
+			s.manifest.Files = make(map[string][]BlockRange)
+		}
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+	if s.manifest.Sizes == nil {
+		s.manifest.Sizes = make(map[string]int64)
+	}
+	s.spools = make(map[string]time.Time)
+	for file := range s.manifest.Sizes {
+		if filepath.Base(file) == archiveParquetSpoolName {
+			s.spools[file] = time.Now()
+		}
+	}
+
+	if cfg.Compression == ArchiveCompressionZstd {
+		if s.encoder, err = zstd.NewWriter(nil); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// SetCloudEvents implements CloudEventsSink. Each JSONL line, and the Parquet payload column, becomes a
+// structured-mode event.
+func (s *ArchiveSink) SetCloudEvents(source string) {
+	s.cloudEventsSource = source
+}
+
+// PublishBlock appends the block to its partition file and records it in the manifest, then writes
+// the Parquet spools that are due.
+func (s *ArchiveSink) PublishBlock(ctx context.Context, block *scanner.Block) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if rangesContain(s.manifest.Ranges, block.Number) {
+		return nil
+	}
+
+	partition := s.partition(block)
+	file := partition + ".jsonl" + s.extension()
+	if len(block.Transactions) > 0 {
+		lines := make([][]byte, len(block.Transactions))
+		for i := range block.Transactions {
+			line, err := marshalTransaction(&block.Transactions[i], s.cloudEventsSource)
+			if err != nil {
+				return err
+			}
+			lines[i] = line
+		}
+
+		size, err := s.appendLines(file, lines)
+		if err != nil {
+			return fmt.Errorf("archive: failed to write block %d: %w", block.Number, err)
+		}
+		if s.config.Parquet {
+			spool := filepath.Join(partition, archiveParquetSpoolName)
+			spoolSize, err := s.spoolParquet(spool, block, lines)
+			if err != nil {
+				return fmt.Errorf("archive: failed to spool parquet rows of block %d: %w", block.Number, err)
+			}
+			if _, ok := s.spools[spool]; !ok {
+				s.spools[spool] = time.Now()
+			}
+			s.manifest.Sizes[spool] = spoolSize
+		}
+		s.manifest.Sizes[file] = size
+		s.manifest.Files[file] = addToRanges(s.manifest.Files[file], block.Number)
+	}
+
+	s.manifest.Ranges = addToRanges(s.manifest.Ranges, block.Number)
+	if err := s.writeManifest(); err != nil {
+		return err
+	}
+
+	// The block is archived; a Parquet file that fails is retried with the next block
+	if err := s.flushParquet(false); err != nil {
+		log.Printf("archive: %v", err)
+	}
+	return nil
+}
+
+// Close writes the pending Parquet spools and releases the compression encoder.
+func (s *ArchiveSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	err := s.flushParquet(true)
+	if s.encoder != nil {
+		if closeErr := s.encoder.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
+
+// partition returns the path of a block's partition relative to the archive directory, without extension.
+func (s *ArchiveSink) partition(block *scanner.Block) string {
+	start := block.Number / s.config.BlockRange * s.config.BlockRange
+	return filepath.Join(
+		"date="+block.Timestamp.UTC().Format("2006-01-02"),
+		fmt.Sprintf("blocks=%d-%d", start, start+s.config.BlockRange-1),
+	)
+}
+
+func (s *ArchiveSink) extension() string {
+	switch s.config.Compression {
+	case ArchiveCompressionGzip:
+		return ".gz"
+	case ArchiveCompressionZstd:
+		return ".zst"
+	default:
+		return ""
+	}
+}
+
+// openForAppend opens a file of the archive for appending, after truncating what was written past
+// the size recorded in the manifest.
+func (s *ArchiveSink) openForAppend(file string) (*os.File, error) {
+	path := filepath.Join(s.config.Directory, file)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, err
+	}
+
+	end, err := f.Seek(0, io.SeekEnd)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	size, ok := s.manifest.Sizes[file]
+	if !ok {
+		// Files of older manifests have no recorded size, so only later appends can be checked;
+		// other files were created for a block the manifest doesn't include
+		if _, archived := s.manifest.Files[file]; archived {
+			size = end
+		}
+		s.manifest.Sizes[file] = size
+	}
+	if end > size {
+		if err := f.Truncate(size); err != nil {
+			f.Close()
+			return nil, err
+		}
+		if _, err := f.Seek(size, io.SeekStart); err != nil {
+			f.Close()
+			return nil, err
+		}
+	}
+	return f, nil
+}
+
+// appendLines appends the lines to a file of the archive as one compressed member, syncs it to disk
+// and returns the new size of the file. Concatenated gzip members and zstd frames decode as a single
+// stream.
+func (s *ArchiveSink) appendLines(file string, lines [][]byte) (int64, error) {
+	f, err := s.openForAppend(file)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	buffered := bufio.NewWriter(f)
+	var w io.WriteCloser
+	switch s.config.Compression {
+	case ArchiveCompressionGzip:
+		w = gzip.NewWriter(buffered)
+	case ArchiveCompressionZstd:
+		s.encoder.Reset(buffered)
+		w = s.encoder
+	default:
+		w = nopWriteCloser{buffered}
+	}
+
+	for _, line := range lines {
+		if _, err := w.Write(append(line, '\n')); err != nil {
+			return 0, err
+		}
+	}
+	if err := w.Close(); err != nil {
+		return 0, err
+	}
+	if err := buffered.Flush(); err != nil {
+		return 0, err
+	}
+	if err := f.Sync(); err != nil {
+		return 0, err
+	}
+	return f.Seek(0, io.SeekCurrent)
+}
+
+// spoolParquet appends the Parquet rows of a block to a spool file as JSON lines, syncs it to disk
+// and returns the new size of the spool.
+func (s *ArchiveSink) spoolParquet(spool string, block *scanner.Block, lines [][]byte) (int64, error) {
+	f, err := s.openForAppend(spool)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	buffered := bufio.NewWriter(f)
+	encoder := json.NewEncoder(buffered)
+	for i := range block.Transactions {
+		if err := encoder.Encode(parquetRow(block, i, lines[i])); err != nil {
+			return 0, err
+		}
+	}
+	if err := buffered.Flush(); err != nil {
+		return 0, err
+	}
+	if err := f.Sync(); err != nil {
+		return 0, err
+	}
+	return f.Seek(0, io.SeekCurrent)
+}
+
+// flushParquet writes the spools that reached the batch size or age, or all of them if force is set,
+// to Parquet files.
+func (s *ArchiveSink) flushParquet(force bool) error {
+	batchSize := int64(s.config.ParquetBatchMB) << 20
+	if batchSize == 0 {
+		batchSize = defaultParquetBatchMB << 20
+	}
+	interval := time.Duration(s.config.ParquetBatchInterval) * time.Second
+	if interval == 0 {
+		interval = defaultParquetBatchInterval * time.Second
+	}
+
+	spools := make([]string, 0, len(s.spools))
+	for spool := range s.spools {
+		spools = append(spools, spool)
+	}
+	sort.Strings(spools)
+
+	for _, spool := range spools {
+		if !force && s.manifest.Sizes[spool] < batchSize && time.Since(s.spools[spool]) < interval {
+			continue
+		}
+		if err := s.writeParquet(spool); err != nil {
+			return fmt.Errorf("failed to write parquet from %s: %w", spool, err)
+		}
+
+		// The spool is only removed once its rows are in a Parquet file. Should the daemon stop
+		// before, the same file is written again from the spool, with any rows added since.
+		if err := os.Remove(filepath.Join(s.config.Directory, spool)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		delete(s.spools, spool)
+		delete(s.manifest.Sizes, spool)
+		if err := s.writeManifest(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parquetRow returns the Parquet row of the transaction at index i of a block.
+func parquetRow(block *scanner.Block, i int, line []byte) archiveParquetRow {
+	tx := &block.Transactions[i]
+	row := archiveParquetRow{
+		TxID:           tx.ID,
+		BlockNumber:    block.Number,
+		BlockTimestamp: block.Timestamp.UnixMilli(),
+		TxIndex:        int32(tx.Index),
+		OwnerAddress:   tx.OwnerAddress(),
+		Success:        tx.IsSuccess(),
+		Payload:        string(line),
+	}
+	if tx.Contract != nil {
+		row.ContractType = tx.Contract.Type
+	}
+	if tx.Receipt != nil {
+		row.EnergyUsageTotal = tx.Receipt.EnergyUsageTotal
+		row.EnergyFee = tx.Receipt.EnergyFee
+		row.NetUsage = tx.Receipt.NetUsage
+		row.NetFee = tx.Receipt.NetFee
+	}
+	return row
+}
+
+// writeParquet writes the rows of a spool to <partition>/batch-<first block>.parquet, where the first
+// block is the one the spool was started with, so writing the same spool again replaces the file.
+func (s *ArchiveSink) writeParquet(spool string) error {
+	in, err := os.Open(filepath.Join(s.config.Directory, spool))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	var rows []archiveParquetRow
+	reader := bufio.NewReader(in)
+	for size := s.manifest.Sizes[spool]; size > 0; {
+		line, err := reader.ReadBytes('\n')
+		if err != nil {
+			return err
+		}
+		size -= int64(len(line))
+
+		var row archiveParquetRow
+		if err := json.Unmarshal(line, &row); err != nil {
+			return err
+		}
+		rows = append(rows, row)
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+
+	path := filepath.Join(s.config.Directory, filepath.Dir(spool), fmt.Sprintf(archiveParquetBatchFileFormat, rows[0].BlockNumber))
+	f, err := os.Create(path + ".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(path + ".tmp")
+	defer f.Close()
+
+	pw, err := writer.NewParquetWriterFromWriter(f, new(archiveParquetRow), 1)
+	if err != nil {
+		return err
+	}
+	switch s.config.Compression {
+	case ArchiveCompressionGzip:
+		pw.CompressionType = parquet.CompressionCodec_GZIP
+	case ArchiveCompressionZstd:
+		pw.CompressionType = parquet.CompressionCodec_ZSTD
+	default:
+		pw.CompressionType = parquet.CompressionCodec_UNCOMPRESSED
+	}
+
+	for _, row := range rows {
+		if err := pw.Write(row); err != nil {
+			return err
+		}
+	}
+	if err := pw.WriteStop(); err != nil {
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(path+".tmp", path)
+}
+
+// writeManifest atomically replaces the manifest file.
+func (s *ArchiveSink) writeManifest() error {
+	s.manifest.UpdatedAt = time.Now().UTC()
+	data, err := json.MarshalIndent(s.manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	path := filepath.Join(s.config.Directory, archiveManifestName)
+	if err := os.WriteFile(path+".tmp", data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(path+".tmp", path)
+}
+
+// rangesContain reports whether a block number is covered by sorted, non-overlapping ranges.
+func rangesContain(ranges []BlockRange, number int64) bool {
+	i := sort.Search(len(ranges), func(i int) bool { return ranges[i].End >= number })
+	return i < len(ranges) && ranges[i].Start <= number
+}
+
+// addToRanges adds a block number to sorted, non-overlapping ranges, merging adjacent ranges.
+func addToRanges(ranges []BlockRange, number int64) []BlockRange {
+	i := sort.Search(len(ranges), func(i int) bool { return ranges[i].End >= number-1 })
+	if i < len(ranges) && ranges[i].Start <= number+1 {
+		if number < ranges[i].Start {
+			ranges[i].Start = number
+		}
+		if number > ranges[i].End {
+			ranges[i].End = number
+			// Merge with the next range if the gap is closed
+			if i+1 < len(ranges) && ranges[i+1].Start == number+1 {
+				ranges[i].End = ranges[i+1].End
+				ranges = append(ranges[:i+1], ranges[i+2:]...)
+			}
+		}
+		return ranges
+	}
+
+	ranges = append(ranges, BlockRange{})
+	copy(ranges[i+1:], ranges[i:])
+	ranges[i] = BlockRange{Start: number, End: number}
+	return ranges
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error { return nil }
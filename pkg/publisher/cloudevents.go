@@ -0,0 +1,57 @@
+package publisher
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/sunbankio/tronevents/pkg/codec"
+	"github.com/sunbankio/tronevents/pkg/models"
+	"github.com/sunbankio/tronevents/pkg/scanner"
+)
+
+const (
+	// FormatNative publishes the transactions as plain JSON documents. It is the default.
+	FormatNative = "native"
+	// FormatCloudEvents publishes the transactions as CloudEvents 1.0 structured-mode JSON events.
+	FormatCloudEvents = codec.FormatCloudEvents
+)
+
+// CloudEventsSink is implemented by the sinks that can publish CloudEvents.
+type CloudEventsSink interface {
+	// SetCloudEvents switches the sink to CloudEvents with the given source, the network name.
+	SetCloudEvents(source string)
+}
+
+// validateFormat checks a publisher or sink format.
+func validateFormat(format string) error {
+	switch format {
+	case "", FormatNative, FormatCloudEvents:
+		return nil
+	default:
+		return fmt.Errorf("unknown format %q", format)
+	}
+}
+
+// marshalTransaction returns the JSON document of a transaction, wrapped in a CloudEvent when
+// cloudEventsSource is set.
+func marshalTransaction(tx *scanner.Transaction, cloudEventsSource string) ([]byte, error) {
+	// Convert to safe transaction to handle invalid times
+	safeTx := models.ConvertTransaction(*tx)
+	if cloudEventsSource == "" {
+		return json.Marshal(safeTx)
+	}
+
+	event, err := codec.TransactionEvent(&safeTx, cloudEventsSource)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(event)
+}
+
+// contentType returns the media type of the documents returned by marshalTransaction.
+func contentType(cloudEventsSource string) string {
+	if cloudEventsSource == "" {
+		return "application/json"
+	}
+	return codec.CloudEventsContentType
+}
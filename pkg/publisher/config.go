@@ -0,0 +1,166 @@
+package publisher
+
+import (
+	"fmt"
+
+	"github.com/sunbankio/tronevents/pkg/codec"
+	"github.com/sunbankio/tronevents/pkg/filter"
+	redisPkg "github.com/sunbankio/tronevents/pkg/redis"
+)
+
+const (
+	// RawModeInline includes the raw protobufs on each published transaction.
+	RawModeInline = "inline"
+	// RawModeStream publishes the raw protobufs to a separate raw stream.
+	RawModeStream = "stream"
+
+	// LogEventsAlongside publishes each log to the logs stream in addition to the transactions.
+	LogEventsAlongside = "alongside"
+	// LogEventsInstead publishes each log to the logs stream instead of the transactions.
+	LogEventsInstead = "instead"
+)
+
+// Config holds the configuration for the event publisher.
+type Config struct {
+	// RawMode controls publishing of the serialized Transaction and TransactionInfo protobufs:
+	// empty (disabled), "inline" or "stream".
+	RawMode string `yaml:"raw_mode"`
+	// Streams overrides the Redis stream names, which default to "<prefix>:<name>".
+	Streams Streams `yaml:"streams"`
+	// Routes additionally publish matching transactions to other streams.
+	Routes []Route `yaml:"routes"`
+	// DefaultStream controls the events stream: "all" (default), "unmatched" or "none".
+	DefaultStream string `yaml:"default_stream"`
+	// EntryIDs selects the stream entry IDs: "auto" (default, assigned by Redis) or "block"
+	// ("<block>-<index>", so Redis rejects duplicates of already published blocks).
+	EntryIDs string `yaml:"entry_ids"`
+	// Network names the chain in the envelope of every entry, e.g. "mainnet" (default) or "nile".
+	Network string `yaml:"network"`
+	// InstanceID identifies this daemon in the "producer" field of every entry; it defaults to the hostname.
+	InstanceID string `yaml:"instance_id"`
+	// Format is the default document format of every sink: "native" (default) or "cloudevents".
+	Format string `yaml:"format"`
+	// Encoding selects the payload format of the stream entries: "json" (default), "msgpack" or "protobuf".
+	Encoding string `yaml:"encoding"`
+	// Compression optionally compresses the payloads: "" (none) or "zstd".
+	Compression string `yaml:"compression"`
+	// Retention controls how long entries are kept on the streams and caps their memory.
+	Retention RetentionConfig `yaml:"retention"`
+	// BlockMarkers wraps the transactions of each block with "block_start" and "block_end" entries
+	// on every transaction stream, so consumers can tell when a block is complete.
+	BlockMarkers bool `yaml:"block_markers"`
+	// LogEvents publishes each log of the transactions as its own entry on the logs stream:
+	// empty (disabled), "alongside" or "instead" of the events and routed streams.
+	LogEvents string `yaml:"log_events"`
+	// Watchlist copies the transactions involving watched addresses to per-address or per-tenant streams.
+	Watchlist WatchlistConfig `yaml:"watchlist"`
+	// RateLimit caps the entries appended to the streams per second.
+	RateLimit RateLimitConfig `yaml:"rate_limit"`
+	// Backpressure pauses backlog publishing while consumer groups lag behind.
+	Backpressure BackpressureConfig `yaml:"backpressure"`
+	// Filter is an expression selecting the transactions published to every sink; see package filter.
+	Filter string `yaml:"filter"`
+}
+
+// Streams holds the names of the Redis streams written by the publisher.
+type Streams struct {
+	Events     string `yaml:"events"`
+	BlockStats string `yaml:"block_stats"`
+	Raw        string `yaml:"raw"`
+	Pending    string `yaml:"pending"`
+	Logs       string `yaml:"logs"`
+}
+
+// ApplyPrefix derives the stream names that are not set from the Redis key prefix,
+// e.g. "<prefix>:events", so daemons with different prefixes can share one Redis.
+func (s *Streams) ApplyPrefix(prefix string) {
+	if prefix == "" {
+		prefix = redisPkg.DefaultPrefix
+	}
+	if s.Events == "" {
+		s.Events = prefix + ":events"
+	}
+	if s.BlockStats == "" {
+		s.BlockStats = prefix + ":block_stats"
+	}
+	if s.Raw == "" {
+		s.Raw = prefix + ":raw"
+	}
+	if s.Pending == "" {
+		s.Pending = prefix + ":pending"
+	}
+	if s.Logs == "" {
+		s.Logs = prefix + ":logs"
+	}
+}
+
+// Validate checks the publisher configuration for invalid values.
+func (c *Config) Validate() error {
+	switch c.RawMode {
+	case "", RawModeInline, RawModeStream:
+	default:
+		return fmt.Errorf("invalid publisher raw_mode %q", c.RawMode)
+	}
+	switch c.DefaultStream {
+	case "", DefaultStreamAll, DefaultStreamUnmatched, DefaultStreamNone:
+	default:
+		return fmt.Errorf("invalid publisher default_stream %q", c.DefaultStream)
+	}
+	switch c.EntryIDs {
+	case "", EntryIDsAuto, EntryIDsBlock:
+	default:
+		return fmt.Errorf("invalid publisher entry_ids %q", c.EntryIDs)
+	}
+	switch c.LogEvents {
+	case "", LogEventsAlongside, LogEventsInstead:
+	default:
+		return fmt.Errorf("invalid publisher log_events %q", c.LogEvents)
+	}
+	if err := c.payloadEncoding().Validate(); err != nil {
+		return fmt.Errorf("invalid publisher encoding: %v", err)
+	}
+	if err := validateFormat(c.Format); err != nil {
+		return fmt.Errorf("invalid publisher format: %v", err)
+	}
+	if err := c.Retention.Validate(); err != nil {
+		return err
+	}
+	if err := c.Watchlist.Validate(); err != nil {
+		return err
+	}
+	if err := c.RateLimit.Validate(); err != nil {
+		return err
+	}
+	if err := c.Backpressure.Validate(); err != nil {
+		return err
+	}
+	if c.Filter != "" {
+		if _, err := filter.Compile(c.Filter); err != nil {
+			return fmt.Errorf("invalid publisher filter: %v", err)
+		}
+	}
+	for i := range c.Routes {
+		if err := c.Routes[i].Validate(); err != nil {
+			return fmt.Errorf("publisher routes[%d]: %v", i, err)
+		}
+	}
+	return nil
+}
+
+// IncludeRaw reports whether the raw protobufs need to be captured by the scanner.
+func (c *Config) IncludeRaw() bool {
+	return c.RawMode != ""
+}
+
+// payloadEncoding returns the encoding of the stream entry payloads.
+func (c *Config) payloadEncoding() codec.Encoding {
+	return codec.Encoding{Format: c.Encoding, Compression: c.Compression}
+}
+
+// compileFilter compiles the publisher filter, returning nil when no filter is configured.
+func (c *Config) compileFilter() (*filter.Filter, error) {
+	if c.Filter == "" {
+		return nil, nil
+	}
+	return filter.Compile(c.Filter)
+}
@@ -0,0 +1,17 @@
+package publisher
+
+import "context"
+
+type backlogKey struct{}
+
+// WithBacklog marks the blocks published with the returned context as backlog blocks, which are
+// published out of order by the workers rather than by the head loop.
+func WithBacklog(ctx context.Context) context.Context {
+	return context.WithValue(ctx, backlogKey{}, true)
+}
+
+// IsBacklog reports whether the context was marked by WithBacklog.
+func IsBacklog(ctx context.Context) bool {
+	backlog, _ := ctx.Value(backlogKey{}).(bool)
+	return backlog
+}
@@ -0,0 +1,89 @@
+package publisher
+
+import (
+	"os"
+
+	"github.com/sunbankio/tronevents/pkg/scanner"
+)
+
+// SchemaVersion is the version of the stream entry envelope and payloads, stored in the "v" field.
+// It is incremented on changes that existing consumers cannot handle.
+const SchemaVersion = 1
+
+// Entry types, stored in the "type" field of the envelope.
+const (
+	// EntryTypeTransaction is a transaction, or its raw protobufs on the raw stream.
+	EntryTypeTransaction = "transaction"
+	// EntryTypeLog is a single log of a transaction on the logs stream.
+	EntryTypeLog = "log"
+	// EntryTypeBlock is the aggregate statistics of a block.
+	EntryTypeBlock = "block"
+	// EntryTypeRetraction withdraws the transactions of a block that left the chain. It is reserved for
+	// consumers to handle; blocks are currently only published once they are final.
+	EntryTypeRetraction = "retraction"
+	// EntryTypeBlockStart is the entry preceding the transactions of a block.
+	EntryTypeBlockStart = "block_start"
+	// EntryTypeBlockEnd is the entry following the transactions of a block.
+	EntryTypeBlockEnd = "block_end"
+	// EntryTypePending is a transaction that entered the pending pool.
+	EntryTypePending = "pending"
+	// EntryTypeIncluded is a previously pending transaction that appeared in a block.
+	EntryTypeIncluded = "included"
+)
+
+const defaultNetwork = "mainnet"
+
+// envelope builds the metadata fields stored next to the payload of every stream entry, so consumers
+// can route and filter entries without decoding their payloads.
+type envelope struct {
+	network  string
+	producer string
+}
+
+// newEnvelope creates the envelope of the configured network and publisher instance.
+func newEnvelope(cfg Config) envelope {
+	e := envelope{network: cfg.networkName(), producer: cfg.InstanceID}
+	if e.producer == "" {
+		e.producer, _ = os.Hostname()
+	}
+	return e
+}
+
+// networkName returns the configured network, "mainnet" by default.
+func (c *Config) networkName() string {
+	if c.Network == "" {
+		return defaultNetwork
+	}
+	return c.Network
+}
+
+// fields returns the envelope of an entry about a block.
+func (e envelope) fields(entryType string, blockNumber int64) []interface{} {
+	return []interface{}{
+		"v", SchemaVersion,
+		"type", entryType,
+		"network", e.network,
+		"block", blockNumber,
+		"producer", e.producer,
+	}
+}
+
+// transactionFields returns the envelope of an entry about a transaction of a block.
+func (e envelope) transactionFields(entryType string, tx *scanner.Transaction) []interface{} {
+	return append(e.fields(entryType, tx.BlockNumber), "index", tx.Index, "txid", tx.ID)
+}
+
+// pendingFields returns the envelope of a pending stream entry; blockNumber is 0 for pending transactions.
+func (e envelope) pendingFields(entryType, txID string, blockNumber int64) []interface{} {
+	fields := []interface{}{
+		"v", SchemaVersion,
+		"type", entryType,
+		"network", e.network,
+		"txid", txID,
+		"producer", e.producer,
+	}
+	if blockNumber != 0 {
+		fields = append(fields, "block", blockNumber)
+	}
+	return fields
+}
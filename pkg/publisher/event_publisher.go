@@ -2,75 +2,693 @@ package publisher
 
 import (
 	"context"
-	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/go-redis/redis/v8"
+	"github.com/sunbankio/tronevents/pkg/codec"
+	"github.com/sunbankio/tronevents/pkg/filter"
 	"github.com/sunbankio/tronevents/pkg/models"
 	"github.com/sunbankio/tronevents/pkg/scanner"
+	"golang.org/x/time/rate"
 )
 
 const (
-	streamName = "tron:events"
-	sevenDays  = 201600 // 7 days * 24 hours * 60 mins * 60 secs / 3 secs per block
+	// EntryIDsAuto lets Redis assign the stream entry IDs.
+	EntryIDsAuto = "auto"
+	// EntryIDsBlock derives the stream entry IDs from the block number and transaction index.
+	EntryIDsBlock = "block"
+
+	// backlogStreamSuffix is appended to the stream names of backlog blocks when entry IDs are derived from blocks.
+	backlogStreamSuffix = ":backlog"
 )
 
-// EventPublisher is responsible for publishing events to a Redis stream.
+// backlogPublishScript appends the entries of a backlog block unless the block was already appended.
+// KEYS[1] is the set of published blocks, scored by the time they were appended, and KEYS[2..] the
+// streams; ARGV is the block number, the current time and the oldest entry ID to keep in milliseconds,
+// then for each entry the index of its stream key, its field count and fields. Blocks appended before
+// the oldest entry ID are removed from the set, as their entries are trimmed from the streams.
+var backlogPublishScript = redis.NewScript(`
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', '(' .. ARGV[3])
+if redis.call('ZADD', KEYS[1], 'NX', ARGV[2], ARGV[1]) == 0 then
+	return 0
+end
+local i = 4
+while i <= #ARGV do
+	local n = tonumber(ARGV[i + 1])
+	local args = {'XADD', KEYS[tonumber(ARGV[i])], 'MINID', '~', ARGV[3], '*'}
+	for j = 1, n * 2 do
+		args[#args + 1] = ARGV[i + 1 + j]
+	end
+	redis.call(unpack(args))
+	i = i + 2 + n * 2
+end
+return 1
+`)
+
+// publishOnceScript appends the entries of a block and adds the block to the processed set, unless
+// the block is already in the set. KEYS[1] is the processed set and KEYS[2..] the streams; ARGV is the
+// block number, its processed time, the oldest entry ID to keep ("" to keep all), the count and names
+// of the entryIdentityFields, then for each entry the index of its stream key, its ID ("*" for
+// Redis-assigned), its field count and fields.
+//
+// Every entry is checked before the first write, so a failing script leaves the streams and the set
+// unchanged. An explicit ID that is not past the last ID of its stream is skipped only when the stream
+// already holds it as the same entry, by its identity fields, appended by an earlier attempt; otherwise
+// the script fails without writing.
+var publishOnceScript = redis.NewScript(`
+local identityCount = tonumber(ARGV[4])
+local identity = {}
+for j = 1, identityCount do
+	identity[j] = ARGV[4 + j]
+end
+local first = 5 + identityCount
+
+local function parseID(id)
+	return string.match(id, '^(%d+)%-(%d+)$')
+end
+
+local function less(a, b)
+	local ams, aseq = parseID(a)
+	local bms, bseq = parseID(b)
+	if #ams ~= #bms then
+		return #ams < #bms
+	end
+	if ams ~= bms then
+		return ams < bms
+	end
+	if #aseq ~= #bseq then
+		return #aseq < #bseq
+	end
+	return aseq < bseq
+end
+
+local function sameEntry(fields, first, n)
+	local existing, entry = {}, {}
+	for j = 1, #fields, 2 do
+		existing[fields[j]] = fields[j + 1]
+	end
+	for j = first, first + n * 2 - 1, 2 do
+		entry[ARGV[j]] = ARGV[j + 1]
+	end
+	for _, field in ipairs(identity) do
+		if existing[field] ~= entry[field] then
+			return false
+		end
+	end
+	return true
+end
+
+if redis.call('ZSCORE', KEYS[1], ARGV[1]) then
+	return 0
+end
+if not string.match(ARGV[2], '^%d+$') or (ARGV[3] ~= '' and not string.match(ARGV[3], '^%d+$') and not parseID(ARGV[3])) then
+	return redis.error_reply('invalid processed time ' .. ARGV[2] .. ' or oldest entry ID ' .. ARGV[3])
+end
+
+local last, skip = {}, {}
+local i = first
+while i <= #ARGV do
+	local key, id, n = KEYS[tonumber(ARGV[i])], ARGV[i + 1], tonumber(ARGV[i + 2])
+	if not key or not n or n < 1 or i + 2 + n * 2 > #ARGV then
+		return redis.error_reply('malformed entry at argument ' .. i)
+	end
+	if not last[key] then
+		local keyType = redis.call('TYPE', key).ok
+		last[key] = '0-0'
+		if keyType == 'stream' then
+			local top = redis.call('XREVRANGE', key, '+', '-', 'COUNT', 1)[1]
+			if top then
+				last[key] = top[1]
+			end
+			local info = redis.call('XINFO', 'STREAM', key)
+			for j = 1, #info, 2 do
+				if info[j] == 'last-generated-id' and less(last[key], info[j + 1]) then
+					last[key] = info[j + 1]
+				end
+			end
+		elseif keyType ~= 'none' then
+			return redis.error_reply('WRONGTYPE ' .. key .. ' is not a stream')
+		end
+	end
+	if id == '*' then
+		last[key] = '*'
+	elseif not parseID(id) then
+		return redis.error_reply('invalid entry ID ' .. id)
+	elseif last[key] ~= '*' and less(last[key], id) then
+		last[key] = id
+	else
+		local existing = redis.call('XRANGE', key, id, id)[1]
+		if not existing or not sameEntry(existing[2], i + 3, n) then
+			return redis.error_reply('entry ' .. id .. ' of stream ' .. key .. ' is not an entry of block ' .. ARGV[1] .. ', but the stream is past it')
+		end
+		skip[i] = true
+	end
+	i = i + 3 + n * 2
+end
+
+i = first
+while i <= #ARGV do
+	local n = tonumber(ARGV[i + 2])
+	if not skip[i] then
+		local args = {'XADD', KEYS[tonumber(ARGV[i])]}
+		if ARGV[3] ~= '' then
+			args[#args + 1] = 'MINID'
+			args[#args + 1] = '~'
+			args[#args + 1] = ARGV[3]
+		end
+		args[#args + 1] = ARGV[i + 1]
+		for j = 1, n * 2 do
+			args[#args + 1] = ARGV[i + 2 + j]
+		end
+		redis.call(unpack(args))
+	end
+	i = i + 3 + n * 2
+end
+redis.call('ZADD', KEYS[1], ARGV[2], ARGV[1])
+return 1
+`)
+
+// entryIdentityFields are the envelope fields identifying the block, transaction or log of an entry.
+// A retried block may differ in its payload, for example by the watchlist tenants, but not in these.
+var entryIdentityFields = []string{"type", "block", "index", "txid", "log_index", "block_hash"}
+
+// streamEntry is an entry to append to a stream.
+type streamEntry struct {
+	stream string
+	id     string        // explicit entry ID, empty to let Redis assign one
+	values []interface{} // field and value pairs
+}
+
+// EventPublisher is the Redis stream sink, responsible for publishing events to a Redis stream.
 type EventPublisher struct {
-	client  *redis.Client
-	limiter <-chan time.Time
+	client   *redis.Client
+	config   Config
+	router   *router
+	filter   *filter.Filter
+	encoding codec.Encoding
+	// rawEncoding encodes the raw stream, which keeps its encoding with CloudEvents
+	rawEncoding codec.Encoding
+	envelope    envelope
+	watch       *watchCache   // nil when the watchlist is disabled
+	limiter     *rate.Limiter // nil when the rate limit is disabled
+	lag         lagState
+	retention   retentionState
+	stop        context.CancelFunc // stops the retention checks
 }
 
 // NewEventPublisher creates a new EventPublisher.
-func NewEventPublisher(client *redis.Client) *EventPublisher {
-	return &EventPublisher{
-		client:  client,
-		limiter: time.Tick(3 * time.Second / 500),
+// Stream names that are not configured default to the "tron" prefix.
+// The retention checks are started when the retention sets a memory limit or the watchlist is enabled,
+// and run until Close.
+func NewEventPublisher(client *redis.Client, cfg Config) *EventPublisher {
+	cfg.Streams.ApplyPrefix("")
+	cfg.Watchlist.ApplyPrefix("")
+	ctx, stop := context.WithCancel(context.Background())
+	p := &EventPublisher{
+		client:      client,
+		config:      cfg,
+		router:      newRouter(cfg),
+		encoding:    cfg.payloadEncoding(),
+		rawEncoding: cfg.payloadEncoding(),
+		envelope:    newEnvelope(cfg),
+		limiter:     cfg.RateLimit.newLimiter(),
+		stop:        stop,
+	}
+	if cfg.Watchlist.Enabled {
+		p.watch = newWatchCache(client, cfg.Watchlist)
 	}
+	if p.retentionChecks() {
+		go p.retain(ctx)
+	}
+	return p
+}
+
+// SetFilter sets the filter selecting the published transactions. Block statistics still cover every transaction.
+func (p *EventPublisher) SetFilter(f *filter.Filter) {
+	p.filter = f
+}
+
+// SetCloudEvents implements CloudEventsSink. Transaction and block statistics payloads become
+// structured-mode events with the "cloudevents" encoding; raw stream payloads are unchanged.
+func (p *EventPublisher) SetCloudEvents(source string) {
+	p.encoding.Format = codec.FormatCloudEvents
+	p.encoding.Source = source
 }
 
 // Publish publishes a transaction to the Redis stream.
 func (p *EventPublisher) Publish(ctx context.Context, tx *scanner.Transaction) error {
-	<-p.limiter
+	return p.PublishBatch(ctx, []*scanner.Transaction{tx})
+}
 
-	// Convert to safe transaction to handle invalid times
-	safeTx := models.ConvertTransaction(*tx)
-	payload, err := json.Marshal(safeTx)
+// PublishBatch publishes multiple transactions to the Redis stream in a single pipeline operation.
+// When entry IDs are derived from blocks, txs must hold the transactions of one block in order.
+func (p *EventPublisher) PublishBatch(ctx context.Context, txs []*scanner.Transaction) error {
+	if len(txs) == 0 {
+		return nil
+	}
+
+	entries, err := p.transactionEntries(ctx, txs)
 	if err != nil {
 		return err
 	}
+	return p.write(ctx, txs[0].BlockNumber, txs[0].BlockTimestamp, entries)
+}
 
-	return p.client.XAdd(ctx, &redis.XAddArgs{
-		Stream:       streamName,
-		MaxLenApprox: sevenDays,
-		Values:       map[string]interface{}{"payload": payload},
-	}).Err()
+// PublishBlock publishes the transactions and the aggregate statistics of a block in a single pipeline operation.
+// With block markers enabled, the transactions are wrapped with block_start and block_end entries.
+func (p *EventPublisher) PublishBlock(ctx context.Context, block *scanner.Block) error {
+	entries, err := p.blockEntries(ctx, block)
+	if err != nil {
+		return err
+	}
+	return p.write(ctx, block.Number, block.Timestamp, entries)
 }
 
-// PublishBatch publishes multiple transactions to the Redis stream in a single pipeline operation.
-func (p *EventPublisher) PublishBatch(ctx context.Context, txs []*scanner.Transaction) error {
-	if len(txs) == 0 {
-		return nil
+// PublishBlockOnce implements ProcessedMarker: it publishes the block like PublishBlock and adds it to
+// the processed set in a single Lua script, which publishes nothing if the block is already in the set.
+func (p *EventPublisher) PublishBlockOnce(ctx context.Context, block *scanner.Block, processed ProcessedSet) (bool, error) {
+	entries, err := p.blockEntries(ctx, block)
+	if err != nil {
+		return false, err
 	}
+	return p.writeOnce(ctx, block.Number, block.Timestamp, entries, processed.Key())
+}
 
-	pipe := p.client.TxPipeline()
+// blockEntries builds the entries of a block: its transactions, their markers and its statistics.
+func (p *EventPublisher) blockEntries(ctx context.Context, block *scanner.Block) ([]streamEntry, error) {
+	entries, err := p.transactionEntries(ctx, transactionPointers(block))
+	if err != nil {
+		return nil, err
+	}
+	if p.config.BlockMarkers {
+		entries = p.withMarkers(block, entries)
+	}
+	statsEntry, err := p.blockStatsEntry(block.Stats())
+	if err != nil {
+		return nil, err
+	}
+	return append(entries, statsEntry), nil
+}
+
+// Close implements Sink and stops the retention checks. The Redis client is owned by the caller and is left open.
+func (p *EventPublisher) Close() error {
+	p.stop()
+	return nil
+}
+
+// transactionEntries builds the entries of the transactions on the streams selected by the routing table
+// and on the watch streams of their watched addresses, and the entries of their logs on the logs stream
+// when log events are enabled.
+func (p *EventPublisher) transactionEntries(ctx context.Context, txs []*scanner.Transaction) ([]streamEntry, error) {
+	var watched map[string]string
+	if p.watch != nil {
+		var err error
+		if watched, err = p.watch.load(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	entries := make([]streamEntry, 0, len(txs))
+	logSeq := 0
+	for index, tx := range txs {
+		if p.filter != nil && !p.filter.Match(tx) {
+			logSeq += len(tx.Logs)
+			continue
+		}
+		if p.config.LogEvents != "" {
+			for logIndex := range tx.Logs {
+				seq := logSeq
+				if p.config.BlockMarkers {
+					seq++ // after the block_start marker
+				}
+				logEntry, err := p.logEntry(tx, logIndex, p.entryID(tx.BlockNumber, seq))
+				if err != nil {
+					return nil, err
+				}
+				entries = append(entries, logEntry)
+				logSeq++
+			}
+		}
+		seq := index
+		if p.config.BlockMarkers {
+			seq++ // after the block_start marker
+		}
+		id := p.entryID(tx.BlockNumber, seq)
 
-	for _, tx := range txs {
 		// Convert to safe transaction to handle invalid times
 		safeTx := models.ConvertTransaction(*tx)
-		payload, err := json.Marshal(safeTx)
+		if p.config.RawMode == RawModeStream && safeTx.Raw != nil {
+			rawEntry, err := p.rawEntry(tx, id)
+			if err != nil {
+				return nil, err
+			}
+			entries = append(entries, rawEntry)
+			safeTx.Raw = nil
+		}
+		var streams []string
+		if p.config.LogEvents != LogEventsInstead {
+			streams = p.router.streams(tx)
+		}
+		if len(watched) > 0 {
+			for _, stream := range p.watch.streams(watched, tx) {
+				if !containsString(streams, stream) {
+					streams = append(streams, stream)
+				}
+			}
+		}
+		if len(streams) == 0 {
+			continue
+		}
+		payload, err := p.encoding.EncodeTransaction(&safeTx)
 		if err != nil {
-			return err
+			return nil, err
+		}
+
+		values := append(p.envelope.transactionFields(EntryTypeTransaction, tx),
+			"encoding", p.encoding.String(),
+			"payload", payload,
+		)
+		for _, stream := range streams {
+			entries = append(entries, streamEntry{stream: stream, id: id, values: values})
 		}
+	}
+	return entries, nil
+}
+
+// rawEntry builds the entry of the raw protobufs of a transaction on the raw stream.
+func (p *EventPublisher) rawEntry(tx *scanner.Transaction, id string) (streamEntry, error) {
+	payload, err := p.rawEncoding.EncodeRaw(tx.Raw)
+	if err != nil {
+		return streamEntry{}, err
+	}
+
+	return streamEntry{
+		stream: p.config.Streams.Raw,
+		id:     id,
+		values: append(p.envelope.transactionFields(EntryTypeTransaction, tx),
+			"encoding", p.rawEncoding.String(),
+			"payload", payload,
+		),
+	}, nil
+}
 
+// logEntry builds the entry of the logIndex-th log of a transaction on the logs stream.
+func (p *EventPublisher) logEntry(tx *scanner.Transaction, logIndex int, id string) (streamEntry, error) {
+	event := models.ConvertLog(tx, logIndex)
+	payload, err := p.encoding.EncodeLog(&event)
+	if err != nil {
+		return streamEntry{}, err
+	}
+
+	return streamEntry{
+		stream: p.config.Streams.Logs,
+		id:     id,
+		values: append(p.envelope.transactionFields(EntryTypeLog, tx),
+			"log_index", logIndex,
+			"address", event.Address,
+			"event_name", event.EventName,
+			"encoding", p.encoding.String(),
+			"payload", payload,
+		),
+	}, nil
+}
+
+// blockStatsEntry builds the entry of the aggregate statistics of a block on the block stats stream.
+func (p *EventPublisher) blockStatsEntry(stats scanner.BlockStats) (streamEntry, error) {
+	payload, err := p.encoding.EncodeBlockStats(&stats)
+	if err != nil {
+		return streamEntry{}, err
+	}
+
+	return streamEntry{
+		stream: p.config.Streams.BlockStats,
+		id:     p.entryID(stats.BlockNumber, 0),
+		values: append(p.envelope.fields(EntryTypeBlock, stats.BlockNumber),
+			"encoding", p.encoding.String(),
+			"payload", payload,
+		),
+	}, nil
+}
+
+// withMarkers wraps the transaction entries of a block with block_start and block_end markers on every
+// transaction stream, including streams that receive no transaction of the block.
+func (p *EventPublisher) withMarkers(block *scanner.Block, entries []streamEntry) []streamEntry {
+	counts := make(map[string]int)
+	for _, entry := range entries {
+		counts[entry.stream]++
+	}
+
+	// The block_end marker follows the last transaction or log entry of the block
+	last := len(block.Transactions)
+	if logCount := blockLogCount(block); logCount > last {
+		last = logCount
+	}
+
+	streams := p.transactionStreams()
+	wrapped := make([]streamEntry, 0, len(entries)+2*len(streams))
+	for _, stream := range streams {
+		wrapped = append(wrapped, p.markerEntry(EntryTypeBlockStart, stream, block, counts[stream], 0))
+	}
+	wrapped = append(wrapped, entries...)
+	for _, stream := range streams {
+		wrapped = append(wrapped, p.markerEntry(EntryTypeBlockEnd, stream, block, counts[stream], last+1))
+	}
+	return wrapped
+}
+
+// markerEntry builds a block marker entry; txCount is the number of transaction or log entries of the block on the stream.
+func (p *EventPublisher) markerEntry(entryType, stream string, block *scanner.Block, txCount, seq int) streamEntry {
+	return streamEntry{
+		stream: stream,
+		id:     p.entryID(block.Number, seq),
+		values: append(p.envelope.fields(entryType, block.Number),
+			"block_hash", block.Hash,
+			"tx_count", txCount,
+		),
+	}
+}
+
+// transactionStreams returns the streams transactions and their logs can be published to.
+func (p *EventPublisher) transactionStreams() []string {
+	var streams []string
+	add := func(stream string) {
+		for _, existing := range streams {
+			if existing == stream {
+				return
+			}
+		}
+		streams = append(streams, stream)
+	}
+
+	if p.config.LogEvents != LogEventsInstead {
+		if p.router.defaultMode != DefaultStreamNone {
+			add(p.config.Streams.Events)
+		}
+		for i := range p.router.routes {
+			add(p.router.routes[i].stream)
+		}
+	}
+	if p.config.LogEvents != "" {
+		add(p.config.Streams.Logs)
+	}
+	if p.config.RawMode == RawModeStream {
+		add(p.config.Streams.Raw)
+	}
+	return streams
+}
+
+// blockLogCount returns the number of logs of the transactions of a block.
+func blockLogCount(block *scanner.Block) int {
+	count := 0
+	for i := range block.Transactions {
+		count += len(block.Transactions[i].Logs)
+	}
+	return count
+}
+
+// entryID returns the explicit entry ID of the seq-th entry of a block, or "" for Redis-assigned IDs.
+func (p *EventPublisher) entryID(blockNumber int64, seq int) string {
+	if p.config.EntryIDs != EntryIDsBlock {
+		return ""
+	}
+	return fmt.Sprintf("%d-%d", blockNumber, seq)
+}
+
+// write appends the entries of a block to their streams, trimming the entries older than the retention.
+// It waits for the rate limiter and, for backlog blocks, for lagging consumers first.
+//
+// With block-derived IDs, Redis rejects entries that were already appended, so retried head blocks are
+// not duplicated. Backlog blocks are older than the head and would be rejected too, so they are appended
+// to "<stream>:backlog" streams with Redis-assigned IDs, once per block.
+func (p *EventPublisher) write(ctx context.Context, blockNumber int64, blockTime time.Time, entries []streamEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	if err := p.throttle(ctx, len(entries)); err != nil {
+		return err
+	}
+	if p.config.EntryIDs == EntryIDsBlock && IsBacklog(ctx) {
+		return p.writeBacklog(ctx, blockNumber, entries)
+	}
+
+	minID := p.minID(blockNumber, blockTime)
+	p.setHeadMinID(minID)
+	pipe := p.client.TxPipeline()
+	for _, entry := range entries {
 		pipe.XAdd(ctx, &redis.XAddArgs{
-			Stream:       streamName,
-			MaxLenApprox: sevenDays,
-			Values:       map[string]interface{}{"payload": payload},
+			Stream: entry.stream,
+			ID:     entry.id,
+			MinID:  minID,
+			Approx: true,
+			Values: entry.values,
 		})
 	}
 
-	// Execute all XAdd commands in a single pipeline
-	_, err := pipe.Exec(ctx)
-	return err
+	// Execute all XAdd commands in a single transaction
+	cmds, err := pipe.Exec(ctx)
+	if err == nil || p.config.EntryIDs != EntryIDsBlock || !isDuplicateIDError(err) {
+		return err
+	}
+	return p.confirmPublished(ctx, entries, cmds)
+}
+
+// confirmPublished checks that the entries whose XADD was rejected as a duplicate ID were published by
+// an earlier attempt, as the same entries by their entryIdentityFields. Redis rejects any ID that is not
+// greater than the last ID of the stream, so an entry missing from the stream means the block was
+// skipped while later blocks were published.
+func (p *EventPublisher) confirmPublished(ctx context.Context, entries []streamEntry, cmds []redis.Cmder) error {
+	pipe := p.client.Pipeline()
+	rejected := make([]int, 0, len(cmds))
+	ranges := make([]*redis.XMessageSliceCmd, 0, len(cmds))
+	for i, cmd := range cmds {
+		cmdErr := cmd.Err()
+		if cmdErr == nil {
+			continue
+		}
+		if !isDuplicateIDError(cmdErr) {
+			return cmdErr
+		}
+		rejected = append(rejected, i)
+		ranges = append(ranges, pipe.XRangeN(ctx, entries[i].stream, entries[i].id, entries[i].id, 1))
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return err
+	}
+
+	for j, i := range rejected {
+		published := ranges[j].Val()
+		if len(published) == 0 {
+			return fmt.Errorf("entry %s of stream %s was never published, but the stream is past it: %w",
+				entries[i].id, entries[i].stream, cmds[i].Err())
+		}
+		if !sameEntry(published[0].Values, entries[i].values) {
+			return fmt.Errorf("entry %s of stream %s is not an entry of block %v: %w",
+				entries[i].id, entries[i].stream, fieldValue(entries[i].values, "block"), cmds[i].Err())
+		}
+	}
+	return nil
+}
+
+// sameEntry reports whether a stream entry holds the same entryIdentityFields as the entry values.
+func sameEntry(published map[string]interface{}, values []interface{}) bool {
+	for _, field := range entryIdentityFields {
+		value := fieldValue(values, field)
+		existing, ok := published[field]
+		if ok != (value != nil) || ok && fmt.Sprint(existing) != fmt.Sprint(value) {
+			return false
+		}
+	}
+	return true
+}
+
+// fieldValue returns the value of a field of the entry values, or nil if the entry has no such field.
+func fieldValue(values []interface{}, field string) interface{} {
+	for i := 0; i+1 < len(values); i += 2 {
+		if values[i] == field {
+			return values[i+1]
+		}
+	}
+	return nil
+}
+
+// writeBacklog appends the entries of a backlog block with the backlog publish script.
+// Backlog streams have Redis-assigned IDs, so their retention is relative to the current time.
+func (p *EventPublisher) writeBacklog(ctx context.Context, blockNumber int64, entries []streamEntry) error {
+	keys := []string{p.config.Streams.Events + backlogStreamSuffix + ":blocks"}
+	keyIndex := make(map[string]int)
+	args := []interface{}{blockNumber, time.Now().UnixMilli(), p.backlogMinID()}
+
+	for _, entry := range entries {
+		stream := entry.stream + backlogStreamSuffix
+		index, ok := keyIndex[stream]
+		if !ok {
+			keys = append(keys, stream)
+			index = len(keys)
+			keyIndex[stream] = index
+		}
+		args = append(args, index, len(entry.values)/2)
+		args = append(args, entry.values...)
+	}
+
+	return backlogPublishScript.Run(ctx, p.client, keys, args...).Err()
+}
+
+// writeOnce appends the entries of a block and adds the block to the processed set with the publish
+// once script, and reports whether the block was published. Like write, it appends backlog blocks with
+// block-derived IDs to the backlog streams; the processed set replaces their backlog block set.
+func (p *EventPublisher) writeOnce(ctx context.Context, blockNumber int64, blockTime time.Time, entries []streamEntry, processedKey string) (bool, error) {
+	if err := p.throttle(ctx, len(entries)); err != nil {
+		return false, err
+	}
+
+	backlog := p.config.EntryIDs == EntryIDsBlock && IsBacklog(ctx)
+	minID := p.minID(blockNumber, blockTime)
+	if backlog {
+		minID = p.backlogMinID()
+	} else {
+		p.setHeadMinID(minID)
+	}
+	keys := []string{processedKey}
+	keyIndex := make(map[string]int)
+	args := []interface{}{blockNumber, time.Now().Unix(), minID, len(entryIdentityFields)}
+	for _, field := range entryIdentityFields {
+		args = append(args, field)
+	}
+
+	for _, entry := range entries {
+		stream, id := entry.stream, entry.id
+		if backlog {
+			stream, id = stream+backlogStreamSuffix, ""
+		}
+		if id == "" {
+			id = "*"
+		}
+		index, ok := keyIndex[stream]
+		if !ok {
+			keys = append(keys, stream)
+			index = len(keys)
+			keyIndex[stream] = index
+		}
+		args = append(args, index, id, len(entry.values)/2)
+		args = append(args, entry.values...)
+	}
+
+	published, err := publishOnceScript.Run(ctx, p.client, keys, args...).Int()
+	if err != nil {
+		return false, err
+	}
+	return published == 1, nil
+}
+
+// backlogMinID returns the oldest entry ID kept on the backlog streams, whose IDs are assigned by Redis.
+func (p *EventPublisher) backlogMinID() string {
+	return strconv.FormatInt(time.Now().Add(-p.config.Retention.duration()).UnixMilli(), 10)
+}
+
+// isDuplicateIDError reports whether XADD rejected an explicit ID that is not greater than the stream's last ID.
+func isDuplicateIDError(err error) bool {
+	return strings.Contains(err.Error(), "equal or smaller than the target stream top item")
 }
@@ -0,0 +1,261 @@
+package publisher
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/sunbankio/tronevents/pkg/scanner"
+)
+
+const testProcessedKey = "tron:processed_blocks"
+
+// redisProcessed is the ProcessedSet of the daemon, a sorted set of block numbers.
+type redisProcessed struct{ client *redis.Client }
+
+func (s redisProcessed) Key() string { return testProcessedKey }
+
+func (s redisProcessed) IsProcessed(ctx context.Context, blockNumber int64) (bool, error) {
+	err := s.client.ZScore(ctx, testProcessedKey, strconv.FormatInt(blockNumber, 10)).Err()
+	if err == redis.Nil {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (s redisProcessed) MarkProcessed(ctx context.Context, blockNumber int64) error {
+	return s.client.ZAdd(ctx, testProcessedKey, &redis.Z{Score: float64(time.Now().Unix()), Member: blockNumber}).Err()
+}
+
+func newTestPublisher(t *testing.T) (*EventPublisher, *redis.Client) {
+	t.Helper()
+
+	client := redis.NewClient(&redis.Options{Addr: miniredis.RunT(t).Addr()})
+	t.Cleanup(func() { client.Close() })
+	p := NewEventPublisher(client, Config{EntryIDs: EntryIDsBlock, InstanceID: "test"})
+	t.Cleanup(func() { p.Close() })
+	return p, client
+}
+
+func testBlock(number int64) *scanner.Block {
+	block := &scanner.Block{Number: number, Hash: "00000000042c1d80", Timestamp: time.Now()}
+	for i, id := range []string{"a1b2", "c3d4"} {
+		block.Transactions = append(block.Transactions, scanner.Transaction{
+			ID:          id,
+			Index:       i,
+			BlockNumber: number,
+			Contract: &scanner.Contract{
+				Type:      "TransferContract",
+				Parameter: scanner.TransferContract{OwnerAddress: "TOwner", ToAddress: "TTo", Amount: int64(i + 1)},
+			},
+		})
+	}
+	return block
+}
+
+// streamIDs returns the entry IDs of the streams, and "processed" if the block is marked processed.
+func streamIDs(t *testing.T, client *redis.Client, blockNumber int64) []string {
+	t.Helper()
+
+	ctx := context.Background()
+	var ids []string
+	for _, stream := range []string{"tron:events", "tron:block_stats"} {
+		entries, err := client.XRange(ctx, stream, "-", "+").Result()
+		if err != nil {
+			t.Fatalf("XRANGE %s: %v", stream, err)
+		}
+		for _, entry := range entries {
+			ids = append(ids, stream+"/"+entry.ID)
+		}
+	}
+	if processed, _ := (redisProcessed{client}).IsProcessed(ctx, blockNumber); processed {
+		ids = append(ids, "processed")
+	}
+	return ids
+}
+
+func TestPublishBlockOnce(t *testing.T) {
+	p, client := newTestPublisher(t)
+	ctx := context.Background()
+	want := "tron:events/100-0 tron:events/100-1 tron:block_stats/100-0 processed"
+
+	published, err := p.PublishBlockOnce(ctx, testBlock(100), redisProcessed{client})
+	if err != nil || !published {
+		t.Fatalf("PublishBlockOnce = %v, %v; want published", published, err)
+	}
+	if got := strings.Join(streamIDs(t, client, 100), " "); got != want {
+		t.Errorf("after publishing: %s, want %s", got, want)
+	}
+
+	// A processed block is skipped, even with its entries trimmed from the streams
+	client.Del(ctx, "tron:events", "tron:block_stats")
+	published, err = p.PublishBlockOnce(ctx, testBlock(100), redisProcessed{client})
+	if err != nil || published {
+		t.Fatalf("PublishBlockOnce of a processed block = %v, %v; want skipped", published, err)
+	}
+	if got := strings.Join(streamIDs(t, client, 100), " "); got != "processed" {
+		t.Errorf("after skipping: %s, want only the processed mark", got)
+	}
+}
+
+func TestPublishBlockOnceReplaysPartlyWrittenBlock(t *testing.T) {
+	p, client := newTestPublisher(t)
+	ctx := context.Background()
+	block := testBlock(100)
+
+	// An earlier attempt appended the first entry, by another instance and with another payload
+	entries, err := p.blockEntries(ctx, block)
+	if err != nil {
+		t.Fatal(err)
+	}
+	values := append([]interface{}{}, entries[0].values...)
+	for i := 0; i < len(values); i += 2 {
+		switch values[i] {
+		case "producer":
+			values[i+1] = "other"
+		case "payload":
+			values[i+1] = "{}"
+		}
+	}
+	client.XAdd(ctx, &redis.XAddArgs{Stream: entries[0].stream, ID: entries[0].id, Values: values})
+
+	published, err := p.PublishBlockOnce(ctx, block, redisProcessed{client})
+	if err != nil || !published {
+		t.Fatalf("PublishBlockOnce = %v, %v; want published", published, err)
+	}
+	want := "tron:events/100-0 tron:events/100-1 tron:block_stats/100-0 processed"
+	if got := strings.Join(streamIDs(t, client, 100), " "); got != want {
+		t.Errorf("streams %s, want %s", got, want)
+	}
+	payload, _ := client.XRange(ctx, "tron:events", "100-0", "100-0").Result()
+	if payload[0].Values["payload"] != "{}" {
+		t.Errorf("the entry of the earlier attempt was replaced")
+	}
+}
+
+func TestPublishBlockOnceRejectsWithoutWriting(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(ctx context.Context, client *redis.Client)
+		want  string // error substring
+		ids   string // entry IDs left on the streams
+	}{
+		{
+			name: "stream past a missing entry",
+			setup: func(ctx context.Context, client *redis.Client) {
+				client.XAdd(ctx, &redis.XAddArgs{Stream: "tron:events", ID: "101-0", Values: []interface{}{"type", "transaction", "block", 101}})
+			},
+			want: "entry 100-0 of stream tron:events is not an entry of block 100, but the stream is past it",
+			ids:  "tron:events/101-0",
+		},
+		{
+			name: "ID holding another transaction",
+			setup: func(ctx context.Context, client *redis.Client) {
+				client.XAdd(ctx, &redis.XAddArgs{Stream: "tron:events", ID: "100-0", Values: []interface{}{
+					"type", "transaction", "block", 100, "index", 0, "txid", "e5f6",
+				}})
+			},
+			want: "entry 100-0 of stream tron:events is not an entry of block 100",
+			ids:  "tron:events/100-0",
+		},
+		{
+			name: "stream of another type",
+			setup: func(ctx context.Context, client *redis.Client) {
+				client.Set(ctx, "tron:block_stats", "x", 0)
+			},
+			want: "WRONGTYPE tron:block_stats is not a stream",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, client := newTestPublisher(t)
+			ctx := context.Background()
+			tt.setup(ctx, client)
+
+			published, err := p.PublishBlockOnce(ctx, testBlock(100), redisProcessed{client})
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("PublishBlockOnce = %v, %v; want error %q", published, err, tt.want)
+			}
+			ids, _ := client.XRange(ctx, "tron:events", "-", "+").Result()
+			var got []string
+			for _, entry := range ids {
+				got = append(got, "tron:events/"+entry.ID)
+			}
+			if strings.Join(got, " ") != tt.ids {
+				t.Errorf("events stream %v, want %s", got, tt.ids)
+			}
+			if processed, _ := (redisProcessed{client}).IsProcessed(ctx, 100); processed {
+				t.Error("block marked processed")
+			}
+		})
+	}
+}
+
+func TestPublishBlockConfirmsRejectedIDs(t *testing.T) {
+	p, client := newTestPublisher(t)
+	ctx := context.Background()
+
+	if err := p.PublishBlock(ctx, testBlock(100)); err != nil {
+		t.Fatalf("PublishBlock: %v", err)
+	}
+	if err := p.PublishBlock(ctx, testBlock(100)); err != nil {
+		t.Fatalf("PublishBlock again: %v", err)
+	}
+	if got, want := strings.Join(streamIDs(t, client, 100), " "), "tron:events/100-0 tron:events/100-1 tron:block_stats/100-0"; got != want {
+		t.Errorf("streams %s, want %s", got, want)
+	}
+
+	other := testBlock(100)
+	other.Transactions[1].ID = "e5f6"
+	if err := p.PublishBlock(ctx, other); err == nil || !strings.Contains(err.Error(), "entry 100-1 of stream tron:events is not an entry of block 100") {
+		t.Errorf("PublishBlock of another transaction at 100-1 = %v", err)
+	}
+}
+
+// failingSink fails to publish while fail is set.
+type failingSink struct {
+	fail      bool
+	published int
+}
+
+func (s *failingSink) PublishBlock(ctx context.Context, block *scanner.Block) error {
+	if s.fail {
+		return errors.New("unavailable")
+	}
+	s.published++
+	return nil
+}
+
+func (s *failingSink) Close() error { return nil }
+
+func TestMultiSinkPublishBlockOnce(t *testing.T) {
+	p, client := newTestPublisher(t)
+	ctx := context.Background()
+	other := &failingSink{fail: true}
+	sink := NewMultiSink(other, p)
+
+	if _, err := PublishOnce(ctx, sink, testBlock(100), redisProcessed{client}); err == nil {
+		t.Fatal("PublishOnce succeeded with a failing sink")
+	}
+	if ids := streamIDs(t, client, 100); len(ids) != 0 {
+		t.Errorf("streams %v after the other sink failed, want none", ids)
+	}
+
+	other.fail = false
+	for i := 0; i < 2; i++ {
+		if _, err := PublishOnce(ctx, sink, testBlock(100), redisProcessed{client}); err != nil {
+			t.Fatalf("PublishOnce: %v", err)
+		}
+	}
+	if other.published != 1 {
+		t.Errorf("other sink published %d times, want 1", other.published)
+	}
+	if got, want := strings.Join(streamIDs(t, client, 100), " "), "tron:events/100-0 tron:events/100-1 tron:block_stats/100-0 processed"; got != want {
+		t.Errorf("streams %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,167 @@
+package publisher
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/sunbankio/tronevents/pkg/scanner"
+	"github.com/twmb/franz-go/pkg/kgo"
+)
+
+const (
+	// SinkTypeKafka publishes to a Kafka topic.
+	SinkTypeKafka = "kafka"
+
+	// KafkaKeyTxID keys records by transaction ID.
+	KafkaKeyTxID = "txid"
+	// KafkaKeyOwner keys records by the owner address of the transaction, keeping an address's transactions ordered.
+	KafkaKeyOwner = "owner"
+
+	defaultKafkaTopic = "tron.events"
+)
+
+// KafkaConfig holds the configuration of the Kafka sink.
+type KafkaConfig struct {
+	Brokers       []string `yaml:"brokers"`
+	Topic         string   `yaml:"topic"`
+	ClientID      string   `yaml:"client_id"`
+	Key           string   `yaml:"key"`             // "txid" (default) or "owner"
+	BatchMaxBytes int32    `yaml:"batch_max_bytes"` // maximum size of a record batch
+	Linger        int      `yaml:"linger"`          // milliseconds to wait for a batch to fill
+	// Idempotent enables the idempotent producer (acks=all, no duplicates on broker retries). Defaults to true.
+	Idempotent *bool `yaml:"idempotent"`
+	Timeout    int   `yaml:"timeout"` // seconds to wait for delivery of a block
+}
+
+// Validate checks the Kafka configuration for invalid values.
+func (c *KafkaConfig) Validate() error {
+	if len(c.Brokers) == 0 {
+		return fmt.Errorf("kafka: at least one broker is required")
+	}
+	switch c.Key {
+	case "", KafkaKeyTxID, KafkaKeyOwner:
+	default:
+		return fmt.Errorf("kafka: invalid key %q", c.Key)
+	}
+	return nil
+}
+
+// KafkaSink publishes each transaction of a block as a Kafka record.
+type KafkaSink struct {
+	client            *kgo.Client
+	config            KafkaConfig
+	timeout           time.Duration
+	cloudEventsSource string
+}
+
+// NewKafkaSink creates a new KafkaSink.
+func NewKafkaSink(cfg KafkaConfig) (*KafkaSink, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	if cfg.Topic == "" {
+		cfg.Topic = defaultKafkaTopic
+	}
+
+	opts := []kgo.Opt{
+		kgo.SeedBrokers(cfg.Brokers...),
+		kgo.DefaultProduceTopic(cfg.Topic),
+	}
+	if cfg.ClientID != "" {
+		opts = append(opts, kgo.ClientID(cfg.ClientID))
+	}
+	if cfg.BatchMaxBytes > 0 {
+		opts = append(opts, kgo.ProducerBatchMaxBytes(cfg.BatchMaxBytes))
+	}
+	if cfg.Linger > 0 {
+		opts = append(opts, kgo.ProducerLinger(time.Duration(cfg.Linger)*time.Millisecond))
+	}
+	if cfg.Idempotent != nil && !*cfg.Idempotent {
+		opts = append(opts, kgo.DisableIdempotentWrite(), kgo.RequiredAcks(kgo.LeaderAck()))
+	} else {
+		// The idempotent producer is the client default and requires acks from all in-sync replicas
+		opts = append(opts, kgo.RequiredAcks(kgo.AllISRAcks()))
+	}
+
+	client, err := kgo.NewClient(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	timeout := time.Duration(cfg.Timeout) * time.Second
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+
+	return &KafkaSink{
+		client:  client,
+		config:  cfg,
+		timeout: timeout,
+	}, nil
+}
+
+// SetCloudEvents implements CloudEventsSink. Records carry structured-mode events with a content-type header.
+func (s *KafkaSink) SetCloudEvents(source string) {
+	s.cloudEventsSource = source
+}
+
+// PublishBlock produces the transactions of a block and waits for their delivery.
+// Any delivery error fails the block so it is retried.
+func (s *KafkaSink) PublishBlock(ctx context.Context, block *scanner.Block) error {
+	if len(block.Transactions) == 0 {
+		return nil
+	}
+
+	records := make([]*kgo.Record, 0, len(block.Transactions))
+	for i := range block.Transactions {
+		tx := &block.Transactions[i]
+
+		payload, err := marshalTransaction(tx, s.cloudEventsSource)
+		if err != nil {
+			return err
+		}
+
+		headers := []kgo.RecordHeader{
+			{Key: "block_number", Value: []byte(strconv.FormatInt(block.Number, 10))},
+			{Key: "txid", Value: []byte(tx.ID)},
+		}
+		if s.cloudEventsSource != "" {
+			headers = append(headers, kgo.RecordHeader{Key: "content-type", Value: []byte(contentType(s.cloudEventsSource))})
+		}
+		records = append(records, &kgo.Record{
+			Key:     []byte(s.recordKey(tx)),
+			Value:   payload,
+			Headers: headers,
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	if err := s.client.ProduceSync(ctx, records...).FirstErr(); err != nil {
+		return fmt.Errorf("kafka: failed to deliver block %d: %w", block.Number, err)
+	}
+	return nil
+}
+
+// Close flushes buffered records and closes the Kafka client.
+func (s *KafkaSink) Close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+
+	err := s.client.Flush(ctx)
+	s.client.Close()
+	return err
+}
+
+// recordKey returns the partitioning key of a transaction.
+func (s *KafkaSink) recordKey(tx *scanner.Transaction) string {
+	if s.config.Key == KafkaKeyOwner {
+		if owner := tx.OwnerAddress(); owner != "" {
+			return owner
+		}
+	}
+	return tx.ID
+}
@@ -0,0 +1,71 @@
+-- Normalized schema written by the postgres sink.
+
+CREATE TABLE IF NOT EXISTS blocks (
+    number            BIGINT PRIMARY KEY,
+    hash              TEXT NOT NULL,
+    timestamp         TIMESTAMPTZ NOT NULL,
+    producer          TEXT NOT NULL DEFAULT '',
+    transaction_count INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE TABLE IF NOT EXISTS transactions (
+    txid                TEXT PRIMARY KEY,
+    block_number        BIGINT NOT NULL REFERENCES blocks (number),
+    tx_index            INTEGER NOT NULL,
+    timestamp           TIMESTAMPTZ,
+    expiration          TIMESTAMPTZ,
+    contract_type       TEXT NOT NULL DEFAULT '',
+    owner_address       TEXT NOT NULL DEFAULT '',
+    success             BOOLEAN NOT NULL,
+    contract_ret        TEXT NOT NULL DEFAULT '',
+    result              TEXT NOT NULL DEFAULT '',
+    energy_usage        BIGINT NOT NULL DEFAULT 0,
+    energy_fee          BIGINT NOT NULL DEFAULT 0,
+    origin_energy_usage BIGINT NOT NULL DEFAULT 0,
+    energy_usage_total  BIGINT NOT NULL DEFAULT 0,
+    net_usage           BIGINT NOT NULL DEFAULT 0,
+    net_fee             BIGINT NOT NULL DEFAULT 0,
+    signers             TEXT[] NOT NULL DEFAULT '{}',
+    raw                 JSONB
+);
+
+CREATE INDEX IF NOT EXISTS transactions_block_number_idx ON transactions (block_number);
+CREATE INDEX IF NOT EXISTS transactions_owner_address_idx ON transactions (owner_address);
+CREATE INDEX IF NOT EXISTS transactions_contract_type_idx ON transactions (contract_type);
+
+CREATE TABLE IF NOT EXISTS contracts (
+    txid          TEXT PRIMARY KEY REFERENCES transactions (txid) ON DELETE CASCADE,
+    type          TEXT NOT NULL,
+    permission_id INTEGER NOT NULL DEFAULT 0,
+    parameter     JSONB
+);
+
+CREATE TABLE IF NOT EXISTS logs (
+    txid       TEXT NOT NULL REFERENCES transactions (txid) ON DELETE CASCADE,
+    log_index  INTEGER NOT NULL,
+    address    TEXT NOT NULL DEFAULT '',
+    event_name TEXT NOT NULL DEFAULT '',
+    signature  TEXT NOT NULL DEFAULT '',
+    inputs     JSONB,
+    PRIMARY KEY (txid, log_index)
+);
+
+CREATE INDEX IF NOT EXISTS logs_address_event_name_idx ON logs (address, event_name);
+
+CREATE TABLE IF NOT EXISTS token_transfers (
+    txid          TEXT NOT NULL REFERENCES transactions (txid) ON DELETE CASCADE,
+    log_index     INTEGER NOT NULL,
+    block_number  BIGINT NOT NULL,
+    token_address TEXT NOT NULL,
+    from_address  TEXT NOT NULL,
+    to_address    TEXT NOT NULL,
+    value         NUMERIC(78, 0) NOT NULL,
+    symbol        TEXT,
+    decimals      INTEGER,
+    amount        TEXT,
+    PRIMARY KEY (txid, log_index)
+);
+
+CREATE INDEX IF NOT EXISTS token_transfers_from_address_idx ON token_transfers (from_address);
+CREATE INDEX IF NOT EXISTS token_transfers_to_address_idx ON token_transfers (to_address);
+CREATE INDEX IF NOT EXISTS token_transfers_token_address_idx ON token_transfers (token_address, block_number);
@@ -0,0 +1,171 @@
+package publisher
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/sunbankio/tronevents/pkg/scanner"
+)
+
+const (
+	// SinkTypeNATS publishes to NATS JetStream.
+	SinkTypeNATS = "nats"
+
+	defaultNATSSubject = "tron.tx.{contract_type}"
+)
+
+// NATSConfig holds the configuration of the NATS JetStream sink.
+type NATSConfig struct {
+	URL         string `yaml:"url"`
+	Credentials string `yaml:"credentials"` // optional path to a NATS credentials file
+	// Subject is the subject template; supports {contract_type}, {owner} and {block_number}.
+	Subject    string `yaml:"subject"`
+	Stream     string `yaml:"stream"`      // optional, rejects publishes not stored in this stream
+	AckTimeout int    `yaml:"ack_timeout"` // seconds to wait for the acknowledgements of a block
+	MaxPending int    `yaml:"max_pending"` // maximum outstanding unacknowledged publishes
+}
+
+// Validate checks the NATS configuration for invalid values.
+func (c *NATSConfig) Validate() error {
+	if c.URL == "" {
+		return fmt.Errorf("nats: url is required")
+	}
+	return nil
+}
+
+// NATSSink publishes each transaction of a block to a JetStream subject.
+// The transaction ID is used as Nats-Msg-Id so the server drops duplicates within its dedupe window.
+type NATSSink struct {
+	conn              *nats.Conn
+	js                jetstream.JetStream
+	config            NATSConfig
+	ackTimeout        time.Duration
+	cloudEventsSource string
+}
+
+// NewNATSSink creates a new NATSSink.
+func NewNATSSink(cfg NATSConfig) (*NATSSink, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	if cfg.Subject == "" {
+		cfg.Subject = defaultNATSSubject
+	}
+
+	opts := []nats.Option{
+		nats.Name("tronevents"),
+		nats.MaxReconnects(-1),
+	}
+	if cfg.Credentials != "" {
+		opts = append(opts, nats.UserCredentials(cfg.Credentials))
+	}
+
+	conn, err := nats.Connect(cfg.URL, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	var jsOpts []jetstream.JetStreamOpt
+	if cfg.MaxPending > 0 {
+		jsOpts = append(jsOpts, jetstream.WithPublishAsyncMaxPending(cfg.MaxPending))
+	}
+	js, err := jetstream.New(conn, jsOpts...)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	ackTimeout := time.Duration(cfg.AckTimeout) * time.Second
+	if ackTimeout <= 0 {
+		ackTimeout = 30 * time.Second
+	}
+
+	return &NATSSink{
+		conn:       conn,
+		js:         js,
+		config:     cfg,
+		ackTimeout: ackTimeout,
+	}, nil
+}
+
+// SetCloudEvents implements CloudEventsSink. Messages carry structured-mode events with a Content-Type header.
+func (s *NATSSink) SetCloudEvents(source string) {
+	s.cloudEventsSource = source
+}
+
+// PublishBlock publishes the transactions of a block and waits for all acknowledgements.
+func (s *NATSSink) PublishBlock(ctx context.Context, block *scanner.Block) error {
+	if len(block.Transactions) == 0 {
+		return nil
+	}
+
+	futures := make([]jetstream.PubAckFuture, 0, len(block.Transactions))
+	for i := range block.Transactions {
+		tx := &block.Transactions[i]
+
+		payload, err := marshalTransaction(tx, s.cloudEventsSource)
+		if err != nil {
+			return err
+		}
+
+		opts := []jetstream.PublishOpt{jetstream.WithMsgID(tx.ID)}
+		if s.config.Stream != "" {
+			opts = append(opts, jetstream.WithExpectStream(s.config.Stream))
+		}
+
+		msg := &nats.Msg{
+			Subject: s.subject(tx),
+			Data:    payload,
+		}
+		if s.cloudEventsSource != "" {
+			msg.Header = nats.Header{"Content-Type": []string{contentType(s.cloudEventsSource)}}
+		}
+		future, err := s.js.PublishMsgAsync(msg, opts...)
+		if err != nil {
+			return fmt.Errorf("nats: failed to publish transaction %s: %w", tx.ID, err)
+		}
+		futures = append(futures, future)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.ackTimeout)
+	defer cancel()
+
+	for _, future := range futures {
+		select {
+		case <-future.Ok():
+		case err := <-future.Err():
+			return fmt.Errorf("nats: failed to publish block %d: %w", block.Number, err)
+		case <-ctx.Done():
+			return fmt.Errorf("nats: waiting for acknowledgements of block %d: %w", block.Number, ctx.Err())
+		}
+	}
+	return nil
+}
+
+// Close drains the NATS connection, waiting for outstanding publishes.
+func (s *NATSSink) Close() error {
+	return s.conn.Drain()
+}
+
+// subject renders the subject template for a transaction.
+func (s *NATSSink) subject(tx *scanner.Transaction) string {
+	contractType := "unknown"
+	if tx.Contract != nil && tx.Contract.Type != "" {
+		contractType = tx.Contract.Type
+	}
+	owner := tx.OwnerAddress()
+	if owner == "" {
+		owner = "unknown"
+	}
+
+	return strings.NewReplacer(
+		"{contract_type}", contractType,
+		"{owner}", owner,
+		"{block_number}", strconv.FormatInt(tx.BlockNumber, 10),
+	).Replace(s.config.Subject)
+}
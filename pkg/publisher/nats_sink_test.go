@@ -0,0 +1,198 @@
+package publisher
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats-server/v2/server"
+	natsserver "github.com/nats-io/nats-server/v2/test"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/sunbankio/tronevents/pkg/scanner"
+)
+
+// runJetStream starts an embedded JetStream server and returns a JetStream client of it.
+func runJetStream(t *testing.T) (*server.Server, jetstream.JetStream) {
+	t.Helper()
+
+	opts := natsserver.DefaultTestOptions
+	opts.Port = -1
+	opts.JetStream = true
+	opts.StoreDir = t.TempDir()
+	srv := natsserver.RunServer(&opts)
+	t.Cleanup(srv.Shutdown)
+
+	conn, err := nats.Connect(srv.ClientURL())
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	t.Cleanup(conn.Close)
+
+	js, err := jetstream.New(conn)
+	if err != nil {
+		t.Fatalf("jetstream: %v", err)
+	}
+	return srv, js
+}
+
+// createTronStream creates the stream capturing the transaction subjects.
+func createTronStream(t *testing.T, js jetstream.JetStream) jetstream.Stream {
+	t.Helper()
+
+	stream, err := js.CreateStream(context.Background(), jetstream.StreamConfig{
+		Name:       "TRON",
+		Subjects:   []string{"tron.tx.>"},
+		Duplicates: time.Minute,
+	})
+	if err != nil {
+		t.Fatalf("create stream: %v", err)
+	}
+	return stream
+}
+
+func newTestNATSSink(t *testing.T, cfg NATSConfig) *NATSSink {
+	t.Helper()
+
+	sink, err := NewNATSSink(cfg)
+	if err != nil {
+		t.Fatalf("NewNATSSink: %v", err)
+	}
+	t.Cleanup(func() { sink.Close() })
+	return sink
+}
+
+func natsTestBlock() *scanner.Block {
+	return &scanner.Block{
+		Number: 1000,
+		Transactions: []scanner.Transaction{
+			{
+				ID:          "aa01",
+				BlockNumber: 1000,
+				Contract: &scanner.Contract{
+					Type: "TransferContract",
+					Parameter: scanner.TransferContract{
+						OwnerAddress: "TOwner1",
+						ToAddress:    "TTo1",
+						Amount:       5,
+					},
+				},
+			},
+			{
+				ID:          "aa02",
+				BlockNumber: 1000,
+				Contract: &scanner.Contract{
+					Type: "TriggerSmartContract",
+					Parameter: scanner.TriggerSmartContract{
+						OwnerAddress:    "TOwner2",
+						ContractAddress: "TContract",
+					},
+				},
+			},
+			{
+				// Unparsed contracts have no type nor owner
+				ID:          "aa03",
+				BlockNumber: 1000,
+				Contract:    &scanner.Contract{},
+			},
+		},
+	}
+}
+
+func TestNATSSinkPublishesToStream(t *testing.T) {
+	srv, js := runJetStream(t)
+	stream := createTronStream(t, js)
+	sink := newTestNATSSink(t, NATSConfig{URL: srv.ClientURL(), Stream: "TRON"})
+
+	if err := sink.PublishBlock(context.Background(), natsTestBlock()); err != nil {
+		t.Fatalf("PublishBlock: %v", err)
+	}
+
+	info, err := stream.Info(context.Background())
+	if err != nil {
+		t.Fatalf("stream info: %v", err)
+	}
+	if info.State.Msgs != 3 {
+		t.Fatalf("stream has %d messages, want 3", info.State.Msgs)
+	}
+
+	wantSubjects := []string{"tron.tx.TransferContract", "tron.tx.TriggerSmartContract", "tron.tx.unknown"}
+	for i, want := range wantSubjects {
+		msg, err := stream.GetMsg(context.Background(), uint64(i+1))
+		if err != nil {
+			t.Fatalf("get message %d: %v", i+1, err)
+		}
+		if msg.Subject != want {
+			t.Errorf("message %d subject = %q, want %q", i+1, msg.Subject, want)
+		}
+		if id := msg.Header.Get(nats.MsgIdHdr); id != natsTestBlock().Transactions[i].ID {
+			t.Errorf("message %d Nats-Msg-Id = %q, want %q", i+1, id, natsTestBlock().Transactions[i].ID)
+		}
+		var tx struct {
+			ID string `json:"id"`
+		}
+		if err := json.Unmarshal(msg.Data, &tx); err != nil {
+			t.Fatalf("message %d payload: %v", i+1, err)
+		}
+		if tx.ID != natsTestBlock().Transactions[i].ID {
+			t.Errorf("message %d payload id = %q, want %q", i+1, tx.ID, natsTestBlock().Transactions[i].ID)
+		}
+	}
+}
+
+func TestNATSSinkSubjectTemplate(t *testing.T) {
+	sink := &NATSSink{config: NATSConfig{Subject: "tron.{block_number}.{contract_type}.{owner}"}}
+
+	block := natsTestBlock()
+	want := []string{
+		"tron.1000.TransferContract.TOwner1",
+		"tron.1000.TriggerSmartContract.TOwner2",
+		"tron.1000.unknown.unknown",
+	}
+	for i := range block.Transactions {
+		if got := sink.subject(&block.Transactions[i]); got != want[i] {
+			t.Errorf("subject of %s = %q, want %q", block.Transactions[i].ID, got, want[i])
+		}
+	}
+}
+
+func TestNATSSinkDeduplicatesRepublishedBlock(t *testing.T) {
+	srv, js := runJetStream(t)
+	stream := createTronStream(t, js)
+	sink := newTestNATSSink(t, NATSConfig{URL: srv.ClientURL()})
+
+	// A retried block is published again with the same message IDs
+	for i := 0; i < 2; i++ {
+		if err := sink.PublishBlock(context.Background(), natsTestBlock()); err != nil {
+			t.Fatalf("PublishBlock %d: %v", i+1, err)
+		}
+	}
+
+	info, err := stream.Info(context.Background())
+	if err != nil {
+		t.Fatalf("stream info: %v", err)
+	}
+	if info.State.Msgs != 3 {
+		t.Errorf("stream has %d messages after republishing, want 3", info.State.Msgs)
+	}
+}
+
+func TestNATSSinkRejectsOtherStream(t *testing.T) {
+	srv, js := runJetStream(t)
+	createTronStream(t, js)
+	sink := newTestNATSSink(t, NATSConfig{URL: srv.ClientURL(), Stream: "OTHER", AckTimeout: 5})
+
+	if err := sink.PublishBlock(context.Background(), natsTestBlock()); err == nil {
+		t.Fatal("PublishBlock succeeded with a stream other than the one capturing the subjects")
+	}
+}
+
+func TestNATSSinkFailsWithoutStream(t *testing.T) {
+	srv, _ := runJetStream(t)
+	sink := newTestNATSSink(t, NATSConfig{URL: srv.ClientURL(), AckTimeout: 5})
+
+	if err := sink.PublishBlock(context.Background(), natsTestBlock()); err == nil {
+		t.Fatal("PublishBlock succeeded without a stream capturing the subjects")
+	}
+}
@@ -0,0 +1,490 @@
+package publisher
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/sunbankio/tronevents/pkg/scanner"
+)
+
+const (
+	segmentSuffix     = ".seg"
+	badSuffix         = ".bad"
+	cursorFile        = "cursor"
+	headFile          = "head"
+	recordHeaderBytes = 8 // payload length and CRC-32, big-endian
+)
+
+// errOutboxFull is returned by Append when the segment files have reached the maximum outbox size.
+var errOutboxFull = errors.New("outbox: full")
+
+func init() {
+	// The contract parameter types of the built-in parsers, so blocks keep their typed parameters
+	// through the outbox. Parsers registered by library users returning other types must register them.
+	for _, parameter := range []interface{}{
+		scanner.AccountCreateContract{},
+		scanner.AccountUpdateContract{},
+		scanner.SetAccountIdContract{},
+		scanner.AccountPermissionUpdateContract{},
+		scanner.TransferContract{},
+		scanner.TransferAssetContract{},
+		scanner.AssetIssueContract{},
+		scanner.ParticipateAssetIssueContract{},
+		scanner.UnfreezeAssetContract{},
+		scanner.UpdateAssetContract{},
+		scanner.DelegateResourceContract{},
+		scanner.UnDelegateResourceContract{},
+		scanner.TriggerSmartContract{},
+		scanner.FreezeBalanceV2Contract{},
+		scanner.FreezeBalanceContract{},
+		scanner.UnfreezeBalanceContract{},
+		scanner.WithdrawBalanceContract{},
+		scanner.UnfreezeBalanceV2Contract{},
+		scanner.WithdrawExpireUnfreezeContract{},
+		scanner.CancelAllUnfreezeV2Contract{},
+		scanner.CreateSmartContract{},
+		scanner.UpdateSettingContract{},
+		scanner.UpdateEnergyLimitContract{},
+		scanner.ClearABIContract{},
+		scanner.VoteAssetContract{},
+		scanner.VoteWitnessContract{},
+		scanner.WitnessCreateContract{},
+		scanner.WitnessUpdateContract{},
+		scanner.ProposalCreateContract{},
+		scanner.ProposalApproveContract{},
+		scanner.ProposalDeleteContract{},
+		scanner.ExchangeCreateContract{},
+		scanner.ExchangeInjectContract{},
+		scanner.ExchangeWithdrawContract{},
+		scanner.ExchangeTransactionContract{},
+		scanner.MarketSellAssetContract{},
+		scanner.MarketCancelOrderContract{},
+		scanner.CustomContract{},
+		scanner.UpdateBrokerageContract{},
+		scanner.ShieldedTransferContract{},
+		// Event input values, and parameters of parsers returning maps
+		new(big.Int),
+		map[string]interface{}{},
+		[]interface{}{},
+	} {
+		gob.Register(parameter)
+	}
+}
+
+// badRecordError is returned by Peek for a record that can't be read or decoded. Size is the number of
+// bytes to quarantine: the record, or the rest of its segment when its length can't be read.
+type badRecordError struct {
+	size int64
+	err  error
+}
+
+func (e *badRecordError) Error() string { return e.err.Error() }
+
+func (e *badRecordError) Unwrap() error { return e.err }
+
+// outbox is a durable FIFO of blocks stored in append-only segment files. Each record is the
+// length and CRC-32 of its payload followed by the payload, the gob of a block, which keeps the
+// typed contract parameters and event values. The cursor file holds the segment and offset of the
+// oldest undelivered record; consumed segments are deleted.
+type outbox struct {
+	dir         string
+	segmentSize int64
+	maxSize     int64 // maximum total size of the segment files, 0 for no limit
+
+	mu          sync.Mutex
+	segments    []int64  // indexes of the segment files, oldest first
+	active      *os.File // last segment, open for appending
+	activeSize  int64
+	size        int64 // total size of the segment files
+	cursorSeg   int64
+	cursorOff   int64
+	pendingRead *os.File // segment being drained, kept open between records
+}
+
+// openOutbox opens the outbox in dir, creating it if needed. A record torn by a crash during
+// append is truncated from the last segment.
+func openOutbox(dir string, segmentSize, maxSize int64) (*outbox, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("outbox: %w", err)
+	}
+	o := &outbox{dir: dir, segmentSize: segmentSize, maxSize: maxSize}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("outbox: %w", err)
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if !strings.HasSuffix(name, segmentSuffix) {
+			continue
+		}
+		index, err := strconv.ParseInt(strings.TrimSuffix(name, segmentSuffix), 10, 64)
+		if err != nil {
+			continue
+		}
+		o.segments = append(o.segments, index)
+	}
+	sort.Slice(o.segments, func(i, j int) bool { return o.segments[i] < o.segments[j] })
+
+	if err := o.loadCursor(); err != nil {
+		return nil, err
+	}
+	// Remove the segments consumed before the cursor was last saved
+	for len(o.segments) > 1 && o.segments[0] < o.cursorSeg {
+		if err := os.Remove(o.segmentPath(o.segments[0])); err != nil {
+			return nil, fmt.Errorf("outbox: %w", err)
+		}
+		o.segments = o.segments[1:]
+	}
+	if len(o.segments) == 0 || o.segments[0] > o.cursorSeg {
+		o.cursorSeg, o.cursorOff = 0, 0
+		if len(o.segments) > 0 {
+			o.cursorSeg = o.segments[0]
+		}
+	}
+
+	if len(o.segments) == 0 {
+		if err := o.roll(); err != nil {
+			return nil, err
+		}
+		o.cursorSeg = o.segments[0]
+		return o, nil
+	}
+	for _, index := range o.segments[:len(o.segments)-1] {
+		info, err := os.Stat(o.segmentPath(index))
+		if err != nil {
+			return nil, fmt.Errorf("outbox: %w", err)
+		}
+		o.size += info.Size()
+	}
+	if err := o.openActive(); err != nil {
+		return nil, err
+	}
+	o.size += o.activeSize
+	return o, nil
+}
+
+// openActive opens the last segment for appending, truncating a torn record at its end.
+func (o *outbox) openActive() error {
+	path := o.segmentPath(o.segments[len(o.segments)-1])
+	f, err := os.OpenFile(path, os.O_RDWR, 0o644)
+	if err != nil {
+		return fmt.Errorf("outbox: %w", err)
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("outbox: %w", err)
+	}
+	var valid int64
+	for {
+		_, n, err := readRecord(f, info.Size()-valid)
+		if err != nil {
+			break
+		}
+		valid += n
+	}
+	if err := f.Truncate(valid); err != nil {
+		f.Close()
+		return fmt.Errorf("outbox: %w", err)
+	}
+	if _, err := f.Seek(valid, io.SeekStart); err != nil {
+		f.Close()
+		return fmt.Errorf("outbox: %w", err)
+	}
+	o.active, o.activeSize = f, valid
+	return nil
+}
+
+// roll starts a new segment after the last one.
+func (o *outbox) roll() error {
+	var index int64
+	if len(o.segments) > 0 {
+		index = o.segments[len(o.segments)-1] + 1
+	}
+	f, err := os.OpenFile(o.segmentPath(index), os.O_CREATE|os.O_RDWR|os.O_EXCL, 0o644)
+	if err != nil {
+		return fmt.Errorf("outbox: %w", err)
+	}
+	if o.active != nil {
+		o.active.Close()
+	}
+	o.segments = append(o.segments, index)
+	o.active, o.activeSize = f, 0
+	return nil
+}
+
+// Append durably adds a block to the outbox. It fails with errOutboxFull when the block would take the
+// segment files past the maximum size. Blocks with contract parameter types that are not registered with
+// gob cannot be encoded.
+func (o *outbox) Append(block *scanner.Block) error {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(block); err != nil {
+		return fmt.Errorf("outbox: encode block %d: %w", block.Number, err)
+	}
+	payload := buf.Bytes()
+	record := make([]byte, recordHeaderBytes, recordHeaderBytes+len(payload))
+	binary.BigEndian.PutUint32(record[0:4], uint32(len(payload)))
+	binary.BigEndian.PutUint32(record[4:8], crc32.ChecksumIEEE(payload))
+	record = append(record, payload...)
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.maxSize > 0 && o.size+int64(len(record)) > o.maxSize {
+		return fmt.Errorf("%w with %d MB buffered, block %d was not appended", errOutboxFull, o.size>>20, block.Number)
+	}
+	if o.activeSize > 0 && o.activeSize+int64(len(record)) > o.segmentSize {
+		if err := o.roll(); err != nil {
+			return err
+		}
+	}
+	if _, err := o.active.Write(record); err != nil {
+		// Drop the partial record so later appends stay readable
+		o.active.Truncate(o.activeSize)
+		o.active.Seek(o.activeSize, io.SeekStart)
+		return fmt.Errorf("outbox: append block %d: %w", block.Number, err)
+	}
+	if err := o.active.Sync(); err != nil {
+		return fmt.Errorf("outbox: sync block %d: %w", block.Number, err)
+	}
+	o.activeSize += int64(len(record))
+	o.size += int64(len(record))
+	return nil
+}
+
+// Empty reports whether every appended block was delivered.
+func (o *outbox) Empty() bool {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.emptyLocked()
+}
+
+func (o *outbox) emptyLocked() bool {
+	return o.cursorSeg == o.segments[len(o.segments)-1] && o.cursorOff >= o.activeSize
+}
+
+// Peek returns the oldest undelivered block and the size of its record, or nil if the outbox is empty.
+// A record that can't be read or decoded is reported with a *badRecordError.
+func (o *outbox) Peek() (*scanner.Block, int64, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.emptyLocked() {
+		return nil, 0, nil
+	}
+	if err := o.skipConsumedLocked(); err != nil {
+		return nil, 0, err
+	}
+	if o.pendingRead == nil {
+		f, err := os.Open(o.segmentPath(o.cursorSeg))
+		if err != nil {
+			return nil, 0, fmt.Errorf("outbox: %w", err)
+		}
+		o.pendingRead = f
+	}
+	if _, err := o.pendingRead.Seek(o.cursorOff, io.SeekStart); err != nil {
+		return nil, 0, fmt.Errorf("outbox: %w", err)
+	}
+	remaining, err := o.segmentEndLocked()
+	if err != nil {
+		return nil, 0, err
+	}
+	remaining -= o.cursorOff
+	payload, n, err := readRecord(o.pendingRead, remaining)
+	if err != nil {
+		if n == 0 {
+			n = remaining
+		}
+		return nil, 0, &badRecordError{size: n, err: fmt.Errorf("outbox: read %s at %d: %w", o.segmentPath(o.cursorSeg), o.cursorOff, err)}
+	}
+
+	var block scanner.Block
+	if err := gob.NewDecoder(bytes.NewReader(payload)).Decode(&block); err != nil {
+		return nil, 0, &badRecordError{size: n, err: fmt.Errorf("outbox: decode %s at %d: %w", o.segmentPath(o.cursorSeg), o.cursorOff, err)}
+	}
+	return &block, n, nil
+}
+
+// segmentEndLocked returns the size of the segment at the cursor.
+func (o *outbox) segmentEndLocked() (int64, error) {
+	if o.cursorSeg == o.segments[len(o.segments)-1] {
+		return o.activeSize, nil
+	}
+	info, err := os.Stat(o.segmentPath(o.cursorSeg))
+	if err != nil {
+		return 0, fmt.Errorf("outbox: %w", err)
+	}
+	return info.Size(), nil
+}
+
+// Quarantine copies the next n bytes at the cursor, a record returned as bad by Peek, to a file next to
+// the segments and moves the cursor past them. It returns the path of the file.
+func (o *outbox) Quarantine(n int64) (string, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	data := make([]byte, n)
+	f, err := os.Open(o.segmentPath(o.cursorSeg))
+	if err != nil {
+		return "", fmt.Errorf("outbox: %w", err)
+	}
+	_, err = f.ReadAt(data, o.cursorOff)
+	f.Close()
+	if err != nil {
+		return "", fmt.Errorf("outbox: %w", err)
+	}
+	path := filepath.Join(o.dir, fmt.Sprintf("%020d-%d%s", o.cursorSeg, o.cursorOff, badSuffix))
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return "", fmt.Errorf("outbox: %w", err)
+	}
+
+	o.cursorOff += n
+	if err := o.skipConsumedLocked(); err != nil {
+		return "", err
+	}
+	return path, o.saveCursor()
+}
+
+// Advance marks the record returned by Peek as delivered.
+func (o *outbox) Advance(n int64) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.cursorOff += n
+	if err := o.skipConsumedLocked(); err != nil {
+		return err
+	}
+	return o.saveCursor()
+}
+
+// skipConsumedLocked moves the cursor past fully delivered segments other than the active one, deleting them.
+func (o *outbox) skipConsumedLocked() error {
+	for o.cursorSeg != o.segments[len(o.segments)-1] {
+		info, err := os.Stat(o.segmentPath(o.cursorSeg))
+		if err != nil {
+			return fmt.Errorf("outbox: %w", err)
+		}
+		if o.cursorOff < info.Size() {
+			return nil
+		}
+		if o.pendingRead != nil {
+			o.pendingRead.Close()
+			o.pendingRead = nil
+		}
+		if err := os.Remove(o.segmentPath(o.cursorSeg)); err != nil {
+			return fmt.Errorf("outbox: %w", err)
+		}
+		o.size -= info.Size()
+		o.segments = o.segments[1:]
+		o.cursorSeg, o.cursorOff = o.segments[0], 0
+	}
+	return nil
+}
+
+// loadCursor reads the cursor file; a missing file starts at the first segment.
+func (o *outbox) loadCursor() error {
+	data, err := os.ReadFile(filepath.Join(o.dir, cursorFile))
+	if errors.Is(err, os.ErrNotExist) {
+		if len(o.segments) > 0 {
+			o.cursorSeg = o.segments[0]
+		}
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("outbox: %w", err)
+	}
+	if _, err := fmt.Sscanf(string(data), "%d %d", &o.cursorSeg, &o.cursorOff); err != nil {
+		return fmt.Errorf("outbox: invalid cursor %q: %w", data, err)
+	}
+	return nil
+}
+
+// saveCursor atomically replaces the cursor file.
+func (o *outbox) saveCursor() error {
+	return o.replaceFile(cursorFile, fmt.Sprintf("%d %d\n", o.cursorSeg, o.cursorOff))
+}
+
+// replaceFile atomically replaces a file of the outbox directory.
+func (o *outbox) replaceFile(name, content string) error {
+	path := filepath.Join(o.dir, name)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, []byte(content), 0o644); err != nil {
+		return fmt.Errorf("outbox: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("outbox: %w", err)
+	}
+	return nil
+}
+
+// LoadHead returns the last synced block saved with SaveHead, and false if none was saved.
+func (o *outbox) LoadHead() (int64, bool, error) {
+	data, err := os.ReadFile(filepath.Join(o.dir, headFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("outbox: %w", err)
+	}
+	head, err := strconv.ParseInt(strings.TrimSpace(string(data)), 10, 64)
+	if err != nil {
+		return 0, false, fmt.Errorf("outbox: invalid head %q: %w", data, err)
+	}
+	return head, true, nil
+}
+
+// SaveHead atomically replaces the head file with the last synced block.
+func (o *outbox) SaveHead(head int64) error {
+	return o.replaceFile(headFile, strconv.FormatInt(head, 10)+"\n")
+}
+
+// Close closes the segment files.
+func (o *outbox) Close() error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.pendingRead != nil {
+		o.pendingRead.Close()
+		o.pendingRead = nil
+	}
+	return o.active.Close()
+}
+
+func (o *outbox) segmentPath(index int64) string {
+	return filepath.Join(o.dir, fmt.Sprintf("%020d%s", index, segmentSuffix))
+}
+
+// readRecord reads the record at the current position of r, within the next limit bytes, and returns
+// its payload and size. On a checksum mismatch, the size of the record is returned with the error.
+func readRecord(r io.Reader, limit int64) ([]byte, int64, error) {
+	var header [recordHeaderBytes]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return nil, 0, err
+	}
+	size := int64(recordHeaderBytes) + int64(binary.BigEndian.Uint32(header[0:4]))
+	if size > limit {
+		return nil, 0, fmt.Errorf("record of %d bytes past the end of the segment", size)
+	}
+	payload := make([]byte, size-recordHeaderBytes)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return nil, 0, err
+	}
+	if crc32.ChecksumIEEE(payload) != binary.BigEndian.Uint32(header[4:8]) {
+		return nil, size, errors.New("checksum mismatch")
+	}
+	return payload, size, nil
+}
@@ -0,0 +1,322 @@
+package publisher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/sunbankio/tronevents/pkg/scanner"
+)
+
+const (
+	defaultOutboxSegmentMB    = 64
+	defaultOutboxMaxSizeMB    = 1024
+	defaultOutboxRetrySeconds = 5
+)
+
+// OutboxConfig holds the local disk outbox, which buffers the head blocks the sinks failed to publish.
+type OutboxConfig struct {
+	// Dir is the directory of the outbox segment files; empty (default) disables the outbox.
+	Dir string `yaml:"dir"`
+	// SegmentSizeMB is the size at which a new segment file is started (default 64).
+	SegmentSizeMB int `yaml:"segment_size_mb"`
+	// MaxSizeMB is the size of the segment files at which head blocks are no longer buffered (default 1024).
+	MaxSizeMB int `yaml:"max_size_mb"`
+	// RetryInterval is the number of seconds between attempts to drain the outbox (default 5).
+	RetryInterval int `yaml:"retry_interval"`
+	// MaxAttempts is the number of failed deliveries after which a block is quarantined; 0 (default)
+	// retries it forever, as failures while the sinks are down look the same.
+	MaxAttempts int `yaml:"max_attempts"`
+}
+
+// Validate checks the outbox configuration for invalid values.
+func (c *OutboxConfig) Validate() error {
+	if c.SegmentSizeMB < 0 {
+		return fmt.Errorf("outbox segment_size_mb must not be negative")
+	}
+	if c.MaxSizeMB < 0 {
+		return fmt.Errorf("outbox max_size_mb must not be negative")
+	}
+	if c.RetryInterval < 0 {
+		return fmt.Errorf("outbox retry_interval must not be negative")
+	}
+	if c.MaxAttempts < 0 {
+		return fmt.Errorf("outbox max_attempts must not be negative")
+	}
+	return nil
+}
+
+func (c *OutboxConfig) segmentSize() int64 {
+	if c.SegmentSizeMB == 0 {
+		return defaultOutboxSegmentMB << 20
+	}
+	return int64(c.SegmentSizeMB) << 20
+}
+
+func (c *OutboxConfig) maxSize() int64 {
+	if c.MaxSizeMB == 0 {
+		return defaultOutboxMaxSizeMB << 20
+	}
+	return int64(c.MaxSizeMB) << 20
+}
+
+func (c *OutboxConfig) retryInterval() time.Duration {
+	if c.RetryInterval == 0 {
+		return defaultOutboxRetrySeconds * time.Second
+	}
+	return time.Duration(c.RetryInterval) * time.Second
+}
+
+// HeadCursor stores the last synced block of the main loop, such as storage.LastSyncedStorage.
+type HeadCursor interface {
+	Load(ctx context.Context) (int64, error)
+	Save(ctx context.Context, blockNumber int64) error
+}
+
+// OutboxSink publishes head blocks to the wrapped sink, parking the blocks it fails to publish in a
+// durable outbox on disk. While the outbox holds blocks, new head blocks are appended behind them, and
+// a background loop delivers them in order once the sink recovers.
+//
+// Buffered blocks are checked against and added to the processed set when they are delivered, rather
+// than when they are buffered, and the OutboxSink keeps the last synced block on disk while the head
+// cursor can't be updated, so the main loop keeps buffering head blocks while Redis is down.
+// Delivery is at least once: with sinks that don't record processed blocks atomically, a block may be
+// published again if the daemon stops between publishing it and recording it.
+//
+// Once the outbox reaches its maximum size, head blocks fail to publish as without the outbox. A record
+// that can't be read or decoded, or that failed MaxAttempts deliveries, is quarantined: it is moved to a
+// ".bad" file in the outbox directory, so it no longer holds up the blocks behind it.
+//
+// Backlog blocks are passed through, since the worker queue retries them.
+type OutboxSink struct {
+	sink        Sink
+	outbox      *outbox
+	processed   ProcessedSet
+	cursor      HeadCursor
+	retry       time.Duration
+	maxAttempts int
+	attempts    int // failed deliveries of the oldest buffered block
+	stop        context.CancelFunc
+	done        chan struct{}
+
+	headMu     sync.Mutex
+	headSynced bool // whether the cursor holds the head saved in the outbox
+}
+
+// NewOutboxSink opens the outbox and starts delivering the blocks left in it by a previous run.
+// Delivered blocks are added to the processed set, and the last synced block is saved in the cursor
+// once no block is buffered.
+func NewOutboxSink(sink Sink, cfg OutboxConfig, processed ProcessedSet, cursor HeadCursor) (*OutboxSink, error) {
+	o, err := openOutbox(cfg.Dir, cfg.segmentSize(), cfg.maxSize())
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, stop := context.WithCancel(context.Background())
+	s := &OutboxSink{
+		sink:        sink,
+		outbox:      o,
+		processed:   processed,
+		cursor:      cursor,
+		retry:       cfg.retryInterval(),
+		maxAttempts: cfg.MaxAttempts,
+		stop:        stop,
+		done:        make(chan struct{}),
+		headSynced:  o.Empty(),
+	}
+	go s.drainLoop(ctx)
+	return s, nil
+}
+
+// PublishBlock publishes the block, or appends it to the outbox if the sink fails or earlier blocks are
+// still waiting there. It only fails if the block could not be written to the outbox either.
+func (s *OutboxSink) PublishBlock(ctx context.Context, block *scanner.Block) error {
+	if IsBacklog(ctx) {
+		return s.sink.PublishBlock(ctx, block)
+	}
+
+	if s.outbox.Empty() {
+		err := s.sink.PublishBlock(ctx, block)
+		if err == nil {
+			return nil
+		}
+		log.Printf("outbox: failed to publish block %d, buffering it on disk: %v", block.Number, err)
+	}
+	return s.outbox.Append(block)
+}
+
+// PublishBlockOnce implements ProcessedMarker. While the outbox is empty, it publishes the block with
+// PublishOnce. If that fails, including when the processed set can't be read, or while earlier blocks
+// are buffered, the block is appended to the outbox and reported as published; it is checked against
+// the processed set when delivered. It only fails if the block could not be written to the outbox either.
+func (s *OutboxSink) PublishBlockOnce(ctx context.Context, block *scanner.Block, processed ProcessedSet) (bool, error) {
+	if IsBacklog(ctx) {
+		return PublishOnce(ctx, s.sink, block, processed)
+	}
+
+	if s.outbox.Empty() {
+		published, err := PublishOnce(ctx, s.sink, block, processed)
+		if err == nil {
+			return published, nil
+		}
+		log.Printf("outbox: failed to publish block %d, buffering it on disk: %v", block.Number, err)
+	}
+	if err := s.outbox.Append(block); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Load implements HeadCursor. It returns the last synced block saved in the outbox while the cursor
+// lags behind it, and when the cursor fails to load; otherwise the block of the cursor.
+func (s *OutboxSink) Load(ctx context.Context) (int64, error) {
+	s.headMu.Lock()
+	defer s.headMu.Unlock()
+
+	head, saved, err := s.outbox.LoadHead()
+	if err != nil {
+		return 0, err
+	}
+	if saved && !s.headSynced {
+		return head, nil
+	}
+	cursorHead, err := s.cursor.Load(ctx)
+	if err != nil && saved {
+		log.Printf("outbox: failed to load the last synced block, using block %d saved on disk: %v", head, err)
+		return head, nil
+	}
+	return cursorHead, err
+}
+
+// Save implements HeadCursor. It saves the last synced block in the outbox, and in the cursor unless
+// blocks are buffered; the cursor is then updated once they are delivered.
+func (s *OutboxSink) Save(ctx context.Context, blockNumber int64) error {
+	s.headMu.Lock()
+	defer s.headMu.Unlock()
+
+	if err := s.outbox.SaveHead(blockNumber); err != nil {
+		return err
+	}
+	s.headSynced = false
+	if s.outbox.Empty() {
+		s.syncHeadLocked(ctx, blockNumber)
+	}
+	return nil
+}
+
+// syncHead saves the last synced block of the outbox in the cursor once no block is buffered.
+func (s *OutboxSink) syncHead(ctx context.Context) {
+	s.headMu.Lock()
+	defer s.headMu.Unlock()
+
+	if s.headSynced || !s.outbox.Empty() {
+		return
+	}
+	head, saved, err := s.outbox.LoadHead()
+	if err != nil {
+		log.Printf("outbox: %v", err)
+		return
+	}
+	if !saved {
+		s.headSynced = true
+		return
+	}
+	s.syncHeadLocked(ctx, head)
+}
+
+func (s *OutboxSink) syncHeadLocked(ctx context.Context, head int64) {
+	if err := s.cursor.Save(ctx, head); err != nil {
+		log.Printf("outbox: failed to save last synced block %d, keeping it on disk: %v", head, err)
+		return
+	}
+	s.headSynced = true
+}
+
+// Close stops the delivery loop, closes the wrapped sink and the outbox. Undelivered blocks stay
+// on disk and are delivered by the next run.
+func (s *OutboxSink) Close() error {
+	s.stop()
+	<-s.done
+	err := s.sink.Close()
+	if closeErr := s.outbox.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// drainLoop delivers the buffered blocks every retry interval until ctx is cancelled, then saves the
+// last synced block in the cursor.
+func (s *OutboxSink) drainLoop(ctx context.Context) {
+	defer close(s.done)
+
+	ticker := time.NewTicker(s.retry)
+	defer ticker.Stop()
+	for {
+		if err := s.drain(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("outbox: %v", err)
+		}
+		if ctx.Err() == nil {
+			s.syncHead(ctx)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// drain delivers the buffered blocks in order, stopping at the first failure. Blocks that were already
+// processed, e.g. by a worker or by an earlier delivery, are skipped, and bad records are quarantined.
+func (s *OutboxSink) drain(ctx context.Context) error {
+	delivered := 0
+	defer func() {
+		if delivered > 0 {
+			log.Printf("outbox: delivered %d buffered blocks", delivered)
+		}
+	}()
+
+	for ctx.Err() == nil {
+		block, size, err := s.outbox.Peek()
+		var bad *badRecordError
+		if errors.As(err, &bad) {
+			if err := s.quarantine(bad.size, bad); err != nil {
+				return err
+			}
+			continue
+		}
+		if err != nil || block == nil {
+			return err
+		}
+		if _, err := PublishOnce(ctx, s.sink, block, s.processed); err != nil {
+			s.attempts++
+			if s.maxAttempts == 0 || s.attempts < s.maxAttempts || ctx.Err() != nil {
+				return fmt.Errorf("failed to deliver buffered block %d: %w", block.Number, err)
+			}
+			if err := s.quarantine(size, fmt.Errorf("block %d failed %d deliveries: %w", block.Number, s.attempts, err)); err != nil {
+				return err
+			}
+			continue
+		}
+		s.attempts = 0
+		if err := s.outbox.Advance(size); err != nil {
+			return err
+		}
+		delivered++
+	}
+	return nil
+}
+
+// quarantine moves the oldest buffered record of the given size out of the outbox.
+func (s *OutboxSink) quarantine(size int64, cause error) error {
+	path, err := s.outbox.Quarantine(size)
+	if err != nil {
+		return err
+	}
+	s.attempts = 0
+	log.Printf("outbox: quarantined a buffered record in %s: %v", path, cause)
+	return nil
+}
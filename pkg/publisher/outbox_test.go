@@ -0,0 +1,345 @@
+package publisher
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/sunbankio/tronevents/pkg/scanner"
+)
+
+var errDown = errors.New("redis down")
+
+// fakeRedis stands in for the Redis state and sink of the daemon, failing every call while down.
+type fakeRedis struct {
+	mu        sync.Mutex
+	down      bool
+	poison    int64 // block that always fails to publish
+	published []int64
+	processed map[int64]bool
+	head      int64
+}
+
+func newFakeRedis() *fakeRedis {
+	return &fakeRedis{processed: make(map[int64]bool)}
+}
+
+func (r *fakeRedis) setDown(down bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.down = down
+}
+
+func (r *fakeRedis) PublishBlock(ctx context.Context, block *scanner.Block) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.down {
+		return errDown
+	}
+	if block.Number == r.poison {
+		return errors.New("rejected")
+	}
+	r.published = append(r.published, block.Number)
+	return nil
+}
+
+func (r *fakeRedis) Close() error { return nil }
+
+func (r *fakeRedis) Key() string { return "processed" }
+
+func (r *fakeRedis) IsProcessed(ctx context.Context, blockNumber int64) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.down {
+		return false, errDown
+	}
+	return r.processed[blockNumber], nil
+}
+
+func (r *fakeRedis) MarkProcessed(ctx context.Context, blockNumber int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.down {
+		return errDown
+	}
+	r.processed[blockNumber] = true
+	return nil
+}
+
+// cursor is the HeadCursor of the fake Redis.
+type cursor struct{ *fakeRedis }
+
+func (c cursor) Load(ctx context.Context) (int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.down {
+		return 0, errDown
+	}
+	return c.head, nil
+}
+
+func (c cursor) Save(ctx context.Context, blockNumber int64) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.down {
+		return errDown
+	}
+	c.head = blockNumber
+	return nil
+}
+
+func newTestOutboxSink(t *testing.T, dir string, r *fakeRedis) *OutboxSink {
+	return newTestOutboxSinkConfig(t, OutboxConfig{Dir: dir}, r)
+}
+
+func newTestOutboxSinkConfig(t *testing.T, cfg OutboxConfig, r *fakeRedis) *OutboxSink {
+	t.Helper()
+
+	// The delivery loop only runs at start; the tests drain explicitly
+	cfg.RetryInterval = 3600
+	s, err := NewOutboxSink(r, cfg, r, cursor{r})
+	if err != nil {
+		t.Fatalf("NewOutboxSink: %v", err)
+	}
+	return s
+}
+
+// headStep runs a step of the main loop: load the head, publish the next block once and save the head.
+func headStep(t *testing.T, s *OutboxSink, r *fakeRedis) {
+	t.Helper()
+
+	head, err := s.Load(context.Background())
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	block := &scanner.Block{Number: head + 1}
+	published, err := PublishOnce(context.Background(), s, block, r)
+	if err != nil {
+		t.Fatalf("PublishOnce block %d: %v", block.Number, err)
+	}
+	if !published {
+		t.Fatalf("PublishOnce block %d: not published", block.Number)
+	}
+	if err := s.Save(context.Background(), block.Number); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+}
+
+func TestOutboxSinkTakesOverWhileRedisIsDown(t *testing.T) {
+	r := newFakeRedis()
+	r.head = 100
+	dir := t.TempDir()
+	s := newTestOutboxSink(t, dir, r)
+
+	headStep(t, s, r) // 101 is published directly
+	r.setDown(true)
+	headStep(t, s, r) // 102 to 104 are buffered, with the head on disk
+	headStep(t, s, r)
+	headStep(t, s, r)
+	if len(r.published) != 1 || r.head != 101 {
+		t.Fatalf("while down: published %v, head %d; want [101] and 101", r.published, r.head)
+	}
+
+	// A restart keeps the buffered blocks and the head
+	if err := s.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	s = newTestOutboxSink(t, dir, r)
+	defer s.Close()
+	if head, err := s.Load(context.Background()); err != nil || head != 104 {
+		t.Fatalf("Load after restart = %d, %v; want 104", head, err)
+	}
+	headStep(t, s, r) // 105 is buffered behind the others
+
+	r.setDown(false)
+	headStep(t, s, r) // 106 too, until the outbox is drained
+	if err := s.drain(context.Background()); err != nil {
+		t.Fatalf("drain: %v", err)
+	}
+	s.syncHead(context.Background())
+
+	want := []int64{101, 102, 103, 104, 105, 106}
+	if !reflect.DeepEqual(r.published, want) {
+		t.Errorf("published %v, want %v", r.published, want)
+	}
+	for _, number := range want {
+		if !r.processed[number] {
+			t.Errorf("block %d is not marked processed", number)
+		}
+	}
+	if r.head != 106 {
+		t.Errorf("cursor head = %d, want 106", r.head)
+	}
+
+	// With the outbox empty, blocks are published directly again
+	headStep(t, s, r)
+	if !s.outbox.Empty() || r.published[len(r.published)-1] != 107 || r.head != 107 {
+		t.Errorf("after draining: published %v, head %d; want 107 published directly", r.published, r.head)
+	}
+}
+
+func TestOutboxSinkSkipsProcessedBlocksOnDelivery(t *testing.T) {
+	r := newFakeRedis()
+	s := newTestOutboxSink(t, t.TempDir(), r)
+	defer s.Close()
+
+	r.setDown(true)
+	for _, number := range []int64{10, 11, 10} {
+		if _, err := s.PublishBlockOnce(context.Background(), &scanner.Block{Number: number}, r); err != nil {
+			t.Fatalf("PublishBlockOnce %d: %v", number, err)
+		}
+	}
+	r.setDown(false)
+	r.processed[11] = true // e.g. by a worker
+
+	if err := s.drain(context.Background()); err != nil {
+		t.Fatalf("drain: %v", err)
+	}
+	if want := []int64{10}; !reflect.DeepEqual(r.published, want) {
+		t.Errorf("published %v, want %v", r.published, want)
+	}
+}
+
+func TestOutboxKeepsTypedBlocks(t *testing.T) {
+	amount, _ := new(big.Int).SetString("115792089237316195423570985008687907853269984665640564039457584007913129639935", 10)
+	block := &scanner.Block{
+		Number: 70000000,
+		Transactions: []scanner.Transaction{{
+			ID: "a1b2",
+			Contract: &scanner.Contract{
+				Type:      "TransferContract",
+				Parameter: scanner.TransferContract{OwnerAddress: "TOwner", ToAddress: "TTo", Amount: 1 << 60},
+			},
+			Logs: []scanner.LogInfo{{
+				EventName: "Transfer",
+				Inputs:    []scanner.EventInput{{Name: "value", Type: "uint256", Value: amount}},
+			}},
+		}},
+	}
+
+	dir := t.TempDir()
+	o, err := openOutbox(dir, 1<<20, 0)
+	if err != nil {
+		t.Fatalf("openOutbox: %v", err)
+	}
+	if err := o.Append(block); err != nil {
+		t.Fatalf("Append: %v", err)
+	}
+	o.Close()
+
+	o, err = openOutbox(dir, 1<<20, 0)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	defer o.Close()
+	got, _, err := o.Peek()
+	if err != nil {
+		t.Fatalf("Peek: %v", err)
+	}
+	if !reflect.DeepEqual(got, block) {
+		t.Errorf("Peek = %+v, want %+v", got, block)
+	}
+}
+
+// bufferBlocks appends the blocks to the outbox of the sink while the fake Redis is down.
+func bufferBlocks(t *testing.T, s *OutboxSink, r *fakeRedis, numbers ...int64) {
+	t.Helper()
+
+	r.setDown(true)
+	defer r.setDown(false)
+	for _, number := range numbers {
+		if _, err := s.PublishBlockOnce(context.Background(), &scanner.Block{Number: number}, r); err != nil {
+			t.Fatalf("PublishBlockOnce %d: %v", number, err)
+		}
+	}
+}
+
+func TestOutboxSinkQuarantinesBadRecords(t *testing.T) {
+	r := newFakeRedis()
+	dir := t.TempDir()
+	s := newTestOutboxSink(t, dir, r)
+	defer s.Close()
+	bufferBlocks(t, s, r, 1, 2, 3, 4)
+
+	segment := filepath.Join(dir, "00000000000000000000"+segmentSuffix)
+	data, err := os.ReadFile(segment)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var offsets []int64
+	for off := int64(0); off < int64(len(data)); off += recordHeaderBytes + int64(binary.BigEndian.Uint32(data[off:])) {
+		offsets = append(offsets, off)
+	}
+	// Block 2 fails its checksum, and the length of block 4 runs past the end of the segment
+	data[offsets[1]+recordHeaderBytes] ^= 0xff
+	binary.BigEndian.PutUint32(data[offsets[3]:], 1<<30)
+	if err := os.WriteFile(segment, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.drain(context.Background()); err != nil {
+		t.Fatalf("drain: %v", err)
+	}
+	if want := []int64{1, 3}; !reflect.DeepEqual(r.published, want) {
+		t.Errorf("published %v, want %v", r.published, want)
+	}
+	if !s.outbox.Empty() {
+		t.Error("outbox is not empty")
+	}
+	bad, _ := filepath.Glob(filepath.Join(dir, "*"+badSuffix))
+	if len(bad) != 2 {
+		t.Errorf("quarantined records %v, want 2", bad)
+	}
+}
+
+func TestOutboxSinkQuarantinesAfterMaxAttempts(t *testing.T) {
+	r := newFakeRedis()
+	s := newTestOutboxSinkConfig(t, OutboxConfig{Dir: t.TempDir(), MaxAttempts: 2}, r)
+	defer s.Close()
+	bufferBlocks(t, s, r, 1, 2, 3)
+	r.poison = 2
+
+	if err := s.drain(context.Background()); err == nil {
+		t.Fatal("first drain succeeded with a block that fails to publish")
+	}
+	if err := s.drain(context.Background()); err != nil {
+		t.Fatalf("second drain: %v", err)
+	}
+	if want := []int64{1, 3}; !reflect.DeepEqual(r.published, want) {
+		t.Errorf("published %v, want %v", r.published, want)
+	}
+}
+
+func TestOutboxMaxSize(t *testing.T) {
+	dir := t.TempDir()
+	o, err := openOutbox(dir, 1<<20, 4<<10)
+	if err != nil {
+		t.Fatalf("openOutbox: %v", err)
+	}
+	appended := 0
+	for ; appended < 100; appended++ {
+		if err = o.Append(&scanner.Block{Number: int64(appended), Hash: "00000000042c1d80"}); err != nil {
+			break
+		}
+	}
+	if !errors.Is(err, errOutboxFull) || appended == 0 {
+		t.Fatalf("Append after %d blocks = %v, want errOutboxFull", appended, err)
+	}
+	o.Close()
+
+	// The size of the segments is restored on open
+	o, err = openOutbox(dir, 1<<20, 4<<10)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	defer o.Close()
+	if err := o.Append(&scanner.Block{Number: 100}); !errors.Is(err, errOutboxFull) {
+		t.Errorf("Append after reopening = %v, want errOutboxFull", err)
+	}
+}
@@ -0,0 +1,82 @@
+package publisher
+
+import (
+	"context"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/sunbankio/tronevents/pkg/codec"
+	"github.com/sunbankio/tronevents/pkg/models"
+	"github.com/sunbankio/tronevents/pkg/scanner"
+)
+
+const (
+	pendingMaxLen = 100000
+)
+
+// PendingPublisher publishes pending (mempool) transactions and their inclusion in a block.
+type PendingPublisher struct {
+	client   *redis.Client
+	stream   string
+	encoding codec.Encoding
+	envelope envelope
+}
+
+// NewPendingPublisher creates a new PendingPublisher writing to the configured pending stream.
+func NewPendingPublisher(client *redis.Client, cfg Config) *PendingPublisher {
+	cfg.Streams.ApplyPrefix("")
+	return &PendingPublisher{
+		client:   client,
+		stream:   cfg.Streams.Pending,
+		encoding: cfg.payloadEncoding(),
+		envelope: newEnvelope(cfg),
+	}
+}
+
+// PublishPending publishes newly seen pending transactions to the pending stream.
+func (p *PendingPublisher) PublishPending(ctx context.Context, txs []*scanner.Transaction) error {
+	if len(txs) == 0 {
+		return nil
+	}
+
+	pipe := p.client.TxPipeline()
+
+	for _, tx := range txs {
+		safeTx := models.ConvertTransaction(*tx)
+		payload, err := p.encoding.EncodeTransaction(&safeTx)
+		if err != nil {
+			return err
+		}
+
+		pipe.XAdd(ctx, &redis.XAddArgs{
+			Stream:       p.stream,
+			MaxLenApprox: pendingMaxLen,
+			Values: append(p.envelope.pendingFields(EntryTypePending, tx.ID, 0),
+				"encoding", p.encoding.String(),
+				"payload", payload,
+			),
+		})
+	}
+
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// PublishIncluded publishes an "included" event for each previously pending transaction found in a block.
+func (p *PendingPublisher) PublishIncluded(ctx context.Context, blockNumber int64, txIDs []string) error {
+	if len(txIDs) == 0 {
+		return nil
+	}
+
+	pipe := p.client.TxPipeline()
+
+	for _, txID := range txIDs {
+		pipe.XAdd(ctx, &redis.XAddArgs{
+			Stream:       p.stream,
+			MaxLenApprox: pendingMaxLen,
+			Values:       p.envelope.pendingFields(EntryTypeIncluded, txID, blockNumber),
+		})
+	}
+
+	_, err := pipe.Exec(ctx)
+	return err
+}
@@ -0,0 +1,58 @@
+package publisher
+
+import (
+	"context"
+
+	"github.com/sunbankio/tronevents/pkg/scanner"
+)
+
+// ProcessedSet tracks the processed blocks in a Redis sorted set of block numbers scored by the time
+// they were processed, such as storage.BlockProcessedStorage.
+type ProcessedSet interface {
+	Key() string
+	IsProcessed(ctx context.Context, blockNumber int64) (bool, error)
+	MarkProcessed(ctx context.Context, blockNumber int64) error
+}
+
+// ProcessedMarker is implemented by the sinks that can record a block as processed atomically with
+// publishing it, so a crash between the two cannot publish the block twice.
+type ProcessedMarker interface {
+	// PublishBlockOnce publishes the block and adds it to the processed set in a single step,
+	// unless it is already in the set. It reports whether the block was published.
+	PublishBlockOnce(ctx context.Context, block *scanner.Block, processed ProcessedSet) (bool, error)
+}
+
+// PublishOnce publishes a block unless it was already processed, and records it as processed. With a
+// ProcessedMarker sink both happen atomically: the Redis stream sink publishes and marks the block in a
+// single script, and the FilteredSink, MultiSink and OutboxSink wrapping it pass the block through to
+// it. Other sinks are checked, published and marked in separate steps. It reports whether the block
+// was published.
+func PublishOnce(ctx context.Context, sink Sink, block *scanner.Block, processed ProcessedSet) (bool, error) {
+	if marker, ok := markerOf(sink); ok {
+		return marker.PublishBlockOnce(ctx, block, processed)
+	}
+
+	alreadyProcessed, err := processed.IsProcessed(ctx, block.Number)
+	if err != nil {
+		return false, err
+	}
+	if alreadyProcessed {
+		return false, nil
+	}
+	if err := sink.PublishBlock(ctx, block); err != nil {
+		return false, err
+	}
+	return true, processed.MarkProcessed(ctx, block.Number)
+}
+
+// markerOf returns the ProcessedMarker of a sink. A FilteredSink is only a marker when the sink it wraps
+// is one, as otherwise it publishes and marks blocks in separate steps like any other sink.
+func markerOf(sink Sink) (ProcessedMarker, bool) {
+	if filtered, ok := sink.(*FilteredSink); ok {
+		if _, ok := markerOf(filtered.sink); !ok {
+			return nil, false
+		}
+	}
+	marker, ok := sink.(ProcessedMarker)
+	return marker, ok
+}
@@ -0,0 +1,252 @@
+package publisher
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+const (
+	defaultRetention           = 7 * 24 * time.Hour
+	defaultRetentionCheckEvery = 60
+
+	// blockInterval is the TRON block interval, used to convert retention to block-derived entry IDs.
+	blockInterval = 3 * time.Second
+
+	// memoryTrimRatio is the fraction of entries kept by each trimming round of the memory guard.
+	memoryTrimRatio = 0.75
+)
+
+// RetentionConfig holds the retention of the Redis streams.
+type RetentionConfig struct {
+	// Duration of block time kept on the streams, trimmed with XADD MINID on publish, e.g. "168h"
+	// (default 7 days).
+	Duration time.Duration `yaml:"duration"`
+	// MaxMemoryMB caps the total memory of the streams; the oldest entries are trimmed beyond it. 0 disables the guard.
+	MaxMemoryMB int `yaml:"max_memory_mb"`
+	// CheckInterval is the number of seconds between retention checks, which trim the watch streams and
+	// check the memory limit (default 60).
+	CheckInterval int `yaml:"check_interval"`
+}
+
+// Validate checks the retention configuration for invalid values.
+func (c *RetentionConfig) Validate() error {
+	if c.Duration < 0 {
+		return fmt.Errorf("retention duration must not be negative")
+	}
+	// YAML integers are read as nanoseconds
+	if c.Duration > 0 && c.Duration < time.Second {
+		return fmt.Errorf("retention duration %s is shorter than a second; give it with a unit, e.g. \"168h\"", c.Duration)
+	}
+	if c.MaxMemoryMB < 0 {
+		return fmt.Errorf("retention max_memory_mb must not be negative")
+	}
+	if c.CheckInterval < 0 {
+		return fmt.Errorf("retention check_interval must not be negative")
+	}
+	return nil
+}
+
+func (c *RetentionConfig) duration() time.Duration {
+	if c.Duration == 0 {
+		return defaultRetention
+	}
+	return c.Duration
+}
+
+func (c *RetentionConfig) checkInterval() time.Duration {
+	if c.CheckInterval == 0 {
+		return defaultRetentionCheckEvery * time.Second
+	}
+	return time.Duration(c.CheckInterval) * time.Second
+}
+
+// minID returns the oldest entry ID kept on the streams of a block, or "" if nothing is old enough to trim.
+// Block-derived IDs are trimmed by block number, Redis-assigned IDs by the block time in milliseconds.
+func (p *EventPublisher) minID(blockNumber int64, blockTime time.Time) string {
+	retention := p.config.Retention.duration()
+	if p.config.EntryIDs == EntryIDsBlock {
+		min := blockNumber - int64(retention/blockInterval)
+		if min <= 0 {
+			return ""
+		}
+		return strconv.FormatInt(min, 10)
+	}
+	if blockTime.IsZero() {
+		blockTime = time.Now()
+	}
+	return strconv.FormatInt(blockTime.Add(-retention).UnixMilli(), 10)
+}
+
+// retentionState holds the oldest entry ID kept on the streams as of the last head block.
+type retentionState struct {
+	mu    sync.Mutex
+	minID string
+}
+
+// retentionChecks reports whether the publisher runs the background retention checks.
+func (p *EventPublisher) retentionChecks() bool {
+	return p.config.Retention.MaxMemoryMB > 0 || p.config.Watchlist.Enabled
+}
+
+// setHeadMinID records the oldest entry ID kept as of a head block, for trimming the watch streams.
+func (p *EventPublisher) setHeadMinID(minID string) {
+	if minID == "" {
+		return
+	}
+	p.retention.mu.Lock()
+	defer p.retention.mu.Unlock()
+	p.retention.minID = minID
+}
+
+func (p *EventPublisher) headMinID() string {
+	p.retention.mu.Lock()
+	defer p.retention.mu.Unlock()
+	return p.retention.minID
+}
+
+// retain runs the retention checks until ctx is cancelled: the watch streams are trimmed, and the oldest
+// entries of the streams are trimmed whenever their total memory exceeds the limit.
+func (p *EventPublisher) retain(ctx context.Context) {
+	ticker := time.NewTicker(p.config.Retention.checkInterval())
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if p.watch != nil {
+				if err := p.trimWatchStreams(ctx); err != nil && ctx.Err() == nil {
+					log.Printf("retention: trimming the watch streams failed: %v", err)
+				}
+			}
+			if p.config.Retention.MaxMemoryMB > 0 {
+				if err := p.enforceMaxMemory(ctx); err != nil && ctx.Err() == nil {
+					log.Printf("retention: memory guard failed: %v", err)
+				}
+			}
+		}
+	}
+}
+
+// trimWatchStreams trims every watch stream by the retention. The streams are found by their prefix
+// rather than from the watchlist, so the streams of addresses that are no longer watched, which are not
+// trimmed on publish, are trimmed too.
+func (p *EventPublisher) trimWatchStreams(ctx context.Context) error {
+	streams, err := p.watchStreams(ctx)
+	if err != nil {
+		return err
+	}
+	headMinID, backlogMinID := p.headMinID(), p.backlogMinID()
+	for _, stream := range streams {
+		minID := headMinID
+		if p.config.EntryIDs == EntryIDsBlock && strings.HasSuffix(stream, backlogStreamSuffix) {
+			minID = backlogMinID
+		}
+		if minID == "" {
+			continue
+		}
+		if err := p.client.XTrimMinIDApprox(ctx, stream, minID, 0).Err(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// watchStreams returns the streams named "<stream_prefix>:*", including their backlog streams.
+func (p *EventPublisher) watchStreams(ctx context.Context) ([]string, error) {
+	var streams []string
+	iter := p.client.ScanType(ctx, 0, p.config.Watchlist.StreamPrefix+":*", 1000, "stream").Iterator()
+	for iter.Next(ctx) {
+		streams = append(streams, iter.Val())
+	}
+	return streams, iter.Err()
+}
+
+// enforceMaxMemory trims every stream by a quarter of its entries until the streams fit in the memory limit.
+func (p *EventPublisher) enforceMaxMemory(ctx context.Context) error {
+	limit := int64(p.config.Retention.MaxMemoryMB) << 20
+	streams, err := p.retainedStreams(ctx)
+	if err != nil {
+		return err
+	}
+
+	for {
+		usage, err := p.memoryUsage(ctx, streams)
+		if err != nil || usage <= limit {
+			return err
+		}
+
+		trimmed := false
+		for _, stream := range streams {
+			length, err := p.client.XLen(ctx, stream).Result()
+			if err != nil {
+				return err
+			}
+			if length <= 1 {
+				continue
+			}
+			keep := int64(float64(length) * memoryTrimRatio)
+			n, err := p.client.XTrimMaxLen(ctx, stream, keep).Result()
+			if err != nil {
+				return err
+			}
+			trimmed = trimmed || n > 0
+		}
+		log.Printf("retention: streams use %d MB, above the %d MB limit; trimmed the oldest entries", usage>>20, limit>>20)
+		if !trimmed {
+			return nil
+		}
+	}
+}
+
+// memoryUsage returns the total memory used by the streams in bytes.
+func (p *EventPublisher) memoryUsage(ctx context.Context, streams []string) (int64, error) {
+	var total int64
+	for _, stream := range streams {
+		usage, err := p.client.MemoryUsage(ctx, stream).Result()
+		if err == redis.Nil {
+			continue
+		}
+		if err != nil {
+			return 0, err
+		}
+		total += usage
+	}
+	return total, nil
+}
+
+// retainedStreams returns every stream written by the publisher, including the backlog streams and the
+// watch streams found by their prefix.
+func (p *EventPublisher) retainedStreams(ctx context.Context) ([]string, error) {
+	streams := append(p.transactionStreams(), p.config.Streams.BlockStats)
+	if p.config.EntryIDs == EntryIDsBlock {
+		for _, stream := range streams {
+			streams = append(streams, stream+backlogStreamSuffix)
+		}
+	}
+	if p.watch != nil {
+		watched, err := p.watchStreams(ctx)
+		if err != nil {
+			return nil, err
+		}
+		streams = append(streams, watched...)
+	}
+
+	seen := make(map[string]bool, len(streams))
+	unique := streams[:0]
+	for _, stream := range streams {
+		if !seen[stream] {
+			seen[stream] = true
+			unique = append(unique, stream)
+		}
+	}
+	return unique, nil
+}
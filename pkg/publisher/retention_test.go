@@ -0,0 +1,79 @@
+package publisher
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"gopkg.in/yaml.v2"
+)
+
+func TestRetentionDuration(t *testing.T) {
+	tests := []struct {
+		yaml    string
+		want    time.Duration
+		wantErr bool
+	}{
+		{yaml: "{}", want: 7 * 24 * time.Hour},
+		{yaml: `duration: "168h"`, want: 168 * time.Hour},
+		{yaml: "duration: 90m", want: 90 * time.Minute},
+		{yaml: "duration: 168", wantErr: true}, // nanoseconds
+		{yaml: `duration: "-1h"`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.yaml, func(t *testing.T) {
+			var c RetentionConfig
+			if err := yaml.Unmarshal([]byte(tt.yaml), &c); err != nil {
+				t.Fatalf("Unmarshal: %v", err)
+			}
+			err := c.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() = %v, want error %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && c.duration() != tt.want {
+				t.Errorf("duration() = %s, want %s", c.duration(), tt.want)
+			}
+		})
+	}
+}
+
+func TestTrimWatchStreams(t *testing.T) {
+	client := redis.NewClient(&redis.Options{Addr: miniredis.RunT(t).Addr()})
+	defer client.Close()
+	p := NewEventPublisher(client, Config{
+		EntryIDs:  EntryIDsBlock,
+		Retention: RetentionConfig{Duration: time.Hour},
+		Watchlist: WatchlistConfig{Enabled: true},
+	})
+	defer p.Close()
+	ctx := context.Background()
+
+	watchlist := NewWatchlist(client, "tron:watchlist")
+	if err := watchlist.Add(ctx, "", "TOwner"); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.PublishBlock(ctx, testBlock(100)); err != nil {
+		t.Fatalf("PublishBlock: %v", err)
+	}
+	if n := client.XLen(ctx, "tron:watch:TOwner").Val(); n != 2 {
+		t.Fatalf("watch stream has %d entries, want 2", n)
+	}
+
+	// The stream of a removed address is no longer written, and so no longer trimmed on publish
+	if _, err := watchlist.Remove(ctx, "TOwner"); err != nil {
+		t.Fatal(err)
+	}
+	later := testBlock(100000)
+	later.Transactions = nil
+	if err := p.PublishBlock(ctx, later); err != nil {
+		t.Fatalf("PublishBlock: %v", err)
+	}
+	if err := p.trimWatchStreams(ctx); err != nil {
+		t.Fatalf("trimWatchStreams: %v", err)
+	}
+	if n := client.XLen(ctx, "tron:watch:TOwner").Val(); n != 0 {
+		t.Errorf("watch stream of a removed address has %d entries after trimming, want 0", n)
+	}
+}
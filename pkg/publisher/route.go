@@ -0,0 +1,137 @@
+package publisher
+
+import (
+	"fmt"
+
+	"github.com/sunbankio/tronevents/pkg/scanner"
+)
+
+const (
+	// DefaultStreamAll publishes every transaction to the events stream, in addition to matching routes.
+	DefaultStreamAll = "all"
+	// DefaultStreamUnmatched publishes only the transactions that match no route to the events stream.
+	DefaultStreamUnmatched = "unmatched"
+	// DefaultStreamNone disables the events stream; transactions matching no route are dropped.
+	DefaultStreamNone = "none"
+)
+
+// Route sends the transactions matching all of its predicates to a stream.
+// Each predicate matches if any of its values matches; empty predicates match every transaction.
+type Route struct {
+	Stream        string   `yaml:"stream"`
+	ContractTypes []string `yaml:"contract_types"` // e.g. TriggerSmartContract
+	// ContractAddresses matches the called contract or the contract emitting any of the logs.
+	ContractAddresses []string `yaml:"contract_addresses"`
+	EventNames        []string `yaml:"event_names"` // names of decoded log events, e.g. Transfer
+	Addresses         []string `yaml:"addresses"`   // any involved address, see scanner.Transaction.Addresses
+}
+
+// Validate checks the route for invalid values.
+func (r *Route) Validate() error {
+	if r.Stream == "" {
+		return fmt.Errorf("route stream is required")
+	}
+	return nil
+}
+
+// router evaluates the routing table for a transaction.
+type router struct {
+	routes        []compiledRoute
+	defaultStream string
+	defaultMode   string
+}
+
+type compiledRoute struct {
+	stream            string
+	contractTypes     map[string]bool
+	contractAddresses map[string]bool
+	eventNames        map[string]bool
+	addresses         map[string]bool
+}
+
+// newRouter compiles the routes of the configuration.
+func newRouter(cfg Config) *router {
+	r := &router{
+		defaultStream: cfg.Streams.Events,
+		defaultMode:   cfg.DefaultStream,
+	}
+	if r.defaultMode == "" {
+		r.defaultMode = DefaultStreamAll
+	}
+	for _, route := range cfg.Routes {
+		r.routes = append(r.routes, compiledRoute{
+			stream:            route.Stream,
+			contractTypes:     stringSet(route.ContractTypes),
+			contractAddresses: stringSet(route.ContractAddresses),
+			eventNames:        stringSet(route.EventNames),
+			addresses:         stringSet(route.Addresses),
+		})
+	}
+	return r
+}
+
+// streams returns the distinct streams a transaction is published to, in routing table order.
+func (r *router) streams(tx *scanner.Transaction) []string {
+	streams := make([]string, 0, 1)
+	add := func(stream string) {
+		for _, existing := range streams {
+			if existing == stream {
+				return
+			}
+		}
+		streams = append(streams, stream)
+	}
+
+	if r.defaultMode == DefaultStreamAll {
+		add(r.defaultStream)
+	}
+	matched := false
+	for i := range r.routes {
+		if r.routes[i].matches(tx) {
+			matched = true
+			add(r.routes[i].stream)
+		}
+	}
+	if !matched && r.defaultMode == DefaultStreamUnmatched {
+		add(r.defaultStream)
+	}
+	return streams
+}
+
+func (r *compiledRoute) matches(tx *scanner.Transaction) bool {
+	if len(r.contractTypes) > 0 && (tx.Contract == nil || !r.contractTypes[tx.Contract.Type]) {
+		return false
+	}
+	if len(r.contractAddresses) > 0 && !r.contractAddresses[tx.ContractAddress()] && !r.anyLog(tx, func(log *scanner.LogInfo) bool {
+		return r.contractAddresses[log.Address]
+	}) {
+		return false
+	}
+	if len(r.eventNames) > 0 && !r.anyLog(tx, func(log *scanner.LogInfo) bool {
+		return r.eventNames[log.EventName]
+	}) {
+		return false
+	}
+	if len(r.addresses) > 0 && !r.anyAddress(tx) {
+		return false
+	}
+	return true
+}
+
+func (r *compiledRoute) anyLog(tx *scanner.Transaction, match func(*scanner.LogInfo) bool) bool {
+	for i := range tx.Logs {
+		if match(&tx.Logs[i]) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *compiledRoute) anyAddress(tx *scanner.Transaction) bool {
+	for _, address := range tx.Addresses() {
+		if r.addresses[address] {
+			return true
+		}
+	}
+	return false
+}
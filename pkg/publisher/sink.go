@@ -0,0 +1,287 @@
+package publisher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/sunbankio/tronevents/pkg/codec"
+	"github.com/sunbankio/tronevents/pkg/filter"
+	"github.com/sunbankio/tronevents/pkg/scanner"
+)
+
+const (
+	// SinkTypeRedis publishes to Redis streams. It is the default when no sinks are configured.
+	SinkTypeRedis = "redis"
+)
+
+// Sink is a destination for the transactions of scanned blocks.
+type Sink interface {
+	// PublishBlock publishes the transactions of a block.
+	PublishBlock(ctx context.Context, block *scanner.Block) error
+	// Close flushes and releases the resources held by the sink.
+	Close() error
+}
+
+// SinkConfig holds the configuration of a single sink.
+type SinkConfig struct {
+	Type string `yaml:"type"`
+	// Format overrides the publisher format for this sink: "native" or "cloudevents".
+	Format   string         `yaml:"format"`
+	Kafka    KafkaConfig    `yaml:"kafka"`
+	NATS     NATSConfig     `yaml:"nats"`
+	Webhook  WebhookConfig  `yaml:"webhook"`
+	Postgres PostgresConfig `yaml:"postgres"`
+	Archive  ArchiveConfig  `yaml:"archive"`
+}
+
+// Validate checks the sink configuration for invalid values.
+func (c *SinkConfig) Validate() error {
+	if err := validateFormat(c.Format); err != nil {
+		return err
+	}
+	switch c.Type {
+	case SinkTypeRedis:
+	case SinkTypeKafka:
+		return c.Kafka.Validate()
+	case SinkTypeNATS:
+		return c.NATS.Validate()
+	case SinkTypeWebhook:
+		return c.Webhook.Validate()
+	case SinkTypePostgres:
+		return c.Postgres.Validate()
+	case SinkTypeArchive:
+		return c.Archive.Validate()
+	default:
+		return fmt.Errorf("unknown sink type %q", c.Type)
+	}
+	return nil
+}
+
+// ApplyPrefix derives the Redis keys of the sink that are not set from the Redis key prefix.
+func (c *SinkConfig) ApplyPrefix(prefix string) {
+	if c.Type != SinkTypeWebhook {
+		return
+	}
+	if c.Webhook.QueueKey == "" {
+		c.Webhook.QueueKey = webhookQueueKey(prefix, c.Webhook.URL)
+	}
+	if c.Webhook.DeadLetterKey == "" {
+		c.Webhook.DeadLetterKey = prefix + ":webhook:dead_letter"
+	}
+}
+
+// NewSink creates the sinks described by the configuration, fanning out to all of them.
+// The Redis stream sink is used when no sinks are configured.
+func NewSink(sinkConfigs []SinkConfig, client *redis.Client, cfg Config) (Sink, error) {
+	txFilter, err := cfg.compileFilter()
+	if err != nil {
+		return nil, err
+	}
+	newEventPublisher := func() Sink {
+		publisher := NewEventPublisher(client, cfg)
+		publisher.SetFilter(txFilter)
+		return publisher
+	}
+
+	if len(sinkConfigs) == 0 {
+		sink := newEventPublisher()
+		if err := applyFormat(sink, SinkTypeRedis, cfg.Format, cfg); err != nil {
+			sink.Close()
+			return nil, err
+		}
+		return sink, nil
+	}
+
+	sinks := make([]Sink, 0, len(sinkConfigs))
+	for _, sinkConfig := range sinkConfigs {
+		var sink Sink
+		var err error
+		switch sinkConfig.Type {
+		case SinkTypeRedis:
+			sink = newEventPublisher()
+		case SinkTypeKafka:
+			sink, err = NewKafkaSink(sinkConfig.Kafka)
+		case SinkTypeNATS:
+			sink, err = NewNATSSink(sinkConfig.NATS)
+		case SinkTypeWebhook:
+			sink, err = NewWebhookSink(sinkConfig.Webhook, client)
+		case SinkTypePostgres:
+			sink, err = NewPostgresSink(context.Background(), sinkConfig.Postgres)
+		case SinkTypeArchive:
+			sink, err = NewArchiveSink(sinkConfig.Archive)
+		default:
+			closeSinks(sinks)
+			return nil, fmt.Errorf("unknown sink type %q", sinkConfig.Type)
+		}
+		if err != nil {
+			closeSinks(sinks)
+			return nil, err
+		}
+		format := sinkConfig.Format
+		if format == "" {
+			format = cfg.Format
+		}
+		if err := applyFormat(sink, sinkConfig.Type, format, cfg); err != nil {
+			closeSinks(append(sinks, sink))
+			return nil, err
+		}
+		if txFilter != nil && sinkConfig.Type != SinkTypeRedis {
+			sink = NewFilteredSink(sink, txFilter)
+		}
+		sinks = append(sinks, sink)
+	}
+
+	if len(sinks) == 1 {
+		return sinks[0], nil
+	}
+	return NewMultiSink(sinks...), nil
+}
+
+// applyFormat switches a sink to CloudEvents when selected by the format.
+func applyFormat(sink Sink, sinkType, format string, cfg Config) error {
+	if format != FormatCloudEvents {
+		return nil
+	}
+	cloudEventsSink, ok := sink.(CloudEventsSink)
+	if !ok {
+		return fmt.Errorf("%s sink does not support the %s format", sinkType, format)
+	}
+	if sinkType == SinkTypeRedis && cfg.Encoding != "" && cfg.Encoding != codec.FormatJSON {
+		return fmt.Errorf("the %s format requires the json publisher encoding, not %q", format, cfg.Encoding)
+	}
+	cloudEventsSink.SetCloudEvents(cfg.networkName())
+	return nil
+}
+
+// FilteredSink passes only the transactions matching a filter to the wrapped sink.
+type FilteredSink struct {
+	sink   Sink
+	filter *filter.Filter
+}
+
+// NewFilteredSink creates a new FilteredSink.
+func NewFilteredSink(sink Sink, f *filter.Filter) *FilteredSink {
+	return &FilteredSink{
+		sink:   sink,
+		filter: f,
+	}
+}
+
+// PublishBlock publishes a copy of the block holding only the matching transactions.
+func (s *FilteredSink) PublishBlock(ctx context.Context, block *scanner.Block) error {
+	return s.sink.PublishBlock(ctx, s.filtered(block))
+}
+
+// PublishBlockOnce implements ProcessedMarker, publishing the matching transactions once with the
+// wrapped sink, atomically when it is a ProcessedMarker itself.
+func (s *FilteredSink) PublishBlockOnce(ctx context.Context, block *scanner.Block, processed ProcessedSet) (bool, error) {
+	return PublishOnce(ctx, s.sink, s.filtered(block), processed)
+}
+
+// filtered returns a copy of the block holding only the matching transactions.
+func (s *FilteredSink) filtered(block *scanner.Block) *scanner.Block {
+	filtered := *block
+	filtered.Transactions = make([]scanner.Transaction, 0, len(block.Transactions))
+	for i := range block.Transactions {
+		if s.filter.Match(&block.Transactions[i]) {
+			filtered.Transactions = append(filtered.Transactions, block.Transactions[i])
+		}
+	}
+	return &filtered
+}
+
+// Close closes the wrapped sink.
+func (s *FilteredSink) Close() error {
+	return s.sink.Close()
+}
+
+// MultiSink fans out each block to several sinks.
+type MultiSink struct {
+	sinks []Sink
+}
+
+// NewMultiSink creates a new MultiSink.
+func NewMultiSink(sinks ...Sink) *MultiSink {
+	return &MultiSink{
+		sinks: sinks,
+	}
+}
+
+// PublishBlock publishes the block to all sinks concurrently.
+// It fails if any sink fails, so the block is retried on every sink.
+func (m *MultiSink) PublishBlock(ctx context.Context, block *scanner.Block) error {
+	errs := make([]error, len(m.sinks))
+
+	var wg sync.WaitGroup
+	for i, sink := range m.sinks {
+		wg.Add(1)
+		go func(i int, sink Sink) {
+			defer wg.Done()
+			errs[i] = sink.PublishBlock(ctx, block)
+		}(i, sink)
+	}
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
+
+// PublishBlockOnce implements ProcessedMarker. When exactly one of the sinks is a ProcessedMarker, the
+// block is published to the other sinks first and then, if they all succeed, to the marker, which
+// records it as processed atomically: the block reaches the marker once and the other sinks at least
+// once. With no marker, or several, the block is checked, published and marked in separate steps.
+func (m *MultiSink) PublishBlockOnce(ctx context.Context, block *scanner.Block, processed ProcessedSet) (bool, error) {
+	var marker ProcessedMarker
+	others := make([]Sink, 0, len(m.sinks))
+	markers := 0
+	for _, sink := range m.sinks {
+		if sinkMarker, ok := markerOf(sink); ok {
+			marker = sinkMarker
+			markers++
+			continue
+		}
+		others = append(others, sink)
+	}
+	if markers != 1 {
+		marker, others = nil, m.sinks
+	}
+
+	alreadyProcessed, err := processed.IsProcessed(ctx, block.Number)
+	if err != nil {
+		return false, err
+	}
+	if alreadyProcessed {
+		return false, nil
+	}
+	if err := NewMultiSink(others...).PublishBlock(ctx, block); err != nil {
+		return false, err
+	}
+	if marker == nil {
+		return true, processed.MarkProcessed(ctx, block.Number)
+	}
+	return marker.PublishBlockOnce(ctx, block, processed)
+}
+
+// Close closes all sinks.
+func (m *MultiSink) Close() error {
+	return closeSinks(m.sinks)
+}
+
+func closeSinks(sinks []Sink) error {
+	errs := make([]error, 0, len(sinks))
+	for _, sink := range sinks {
+		errs = append(errs, sink.Close())
+	}
+	return errors.Join(errs...)
+}
+
+// transactionPointers converts the transactions of a block to a slice of pointers for batch publishing.
+func transactionPointers(block *scanner.Block) []*scanner.Transaction {
+	txs := make([]*scanner.Transaction, len(block.Transactions))
+	for i := range block.Transactions {
+		txs[i] = &block.Transactions[i]
+	}
+	return txs
+}
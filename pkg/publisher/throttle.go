@@ -0,0 +1,206 @@
+package publisher
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"golang.org/x/time/rate"
+)
+
+const defaultBackpressureCheckEvery = 5
+
+// RateLimitConfig holds the token-bucket limit of the entries appended to the Redis streams.
+type RateLimitConfig struct {
+	// EntriesPerSecond caps the stream entries appended per second, by head and backlog blocks alike.
+	// 0 (default) disables the limit.
+	EntriesPerSecond float64 `yaml:"entries_per_second"`
+	// Burst is the number of entries that can be appended at once (default: one second of entries).
+	Burst int `yaml:"burst"`
+}
+
+// Validate checks the rate limit configuration for invalid values.
+func (c *RateLimitConfig) Validate() error {
+	if c.EntriesPerSecond < 0 {
+		return fmt.Errorf("rate_limit entries_per_second must not be negative")
+	}
+	if c.Burst < 0 {
+		return fmt.Errorf("rate_limit burst must not be negative")
+	}
+	return nil
+}
+
+// newLimiter creates the limiter of the configuration, or nil when the limit is disabled.
+func (c *RateLimitConfig) newLimiter() *rate.Limiter {
+	if c.EntriesPerSecond == 0 {
+		return nil
+	}
+	burst := c.Burst
+	if burst == 0 {
+		burst = int(c.EntriesPerSecond)
+	}
+	if burst < 1 {
+		burst = 1
+	}
+	return rate.NewLimiter(rate.Limit(c.EntriesPerSecond), burst)
+}
+
+// BackpressureConfig pauses backlog publishing while the consumers of the streams fall behind.
+type BackpressureConfig struct {
+	// MaxLag is the number of entries a consumer group may lag behind before backlog blocks wait;
+	// head blocks are always published. 0 (default) disables backpressure.
+	MaxLag int64 `yaml:"max_lag"`
+	// CheckInterval is the number of seconds between consumer lag checks (default 5).
+	CheckInterval int `yaml:"check_interval"`
+}
+
+// Validate checks the backpressure configuration for invalid values.
+func (c *BackpressureConfig) Validate() error {
+	if c.MaxLag < 0 {
+		return fmt.Errorf("backpressure max_lag must not be negative")
+	}
+	if c.CheckInterval < 0 {
+		return fmt.Errorf("backpressure check_interval must not be negative")
+	}
+	return nil
+}
+
+func (c *BackpressureConfig) checkInterval() time.Duration {
+	if c.CheckInterval == 0 {
+		return defaultBackpressureCheckEvery * time.Second
+	}
+	return time.Duration(c.CheckInterval) * time.Second
+}
+
+// lagState caches the result of the last consumer lag check, shared by the concurrent backlog workers.
+type lagState struct {
+	mu        sync.Mutex
+	lagging   bool
+	checkedAt time.Time
+}
+
+// throttle waits until n entries may be appended: for the rate limiter and, for backlog blocks,
+// until no consumer group lags beyond the backpressure threshold.
+func (p *EventPublisher) throttle(ctx context.Context, n int) error {
+	if p.config.Backpressure.MaxLag > 0 && IsBacklog(ctx) {
+		if err := p.awaitConsumers(ctx); err != nil {
+			return err
+		}
+	}
+	if p.limiter == nil {
+		return nil
+	}
+	// WaitN fails for more entries than the burst, so large blocks wait in chunks
+	for n > 0 {
+		chunk := n
+		if burst := p.limiter.Burst(); chunk > burst {
+			chunk = burst
+		}
+		if err := p.limiter.WaitN(ctx, chunk); err != nil {
+			return err
+		}
+		n -= chunk
+	}
+	return nil
+}
+
+// awaitConsumers blocks while a consumer group of the transaction streams lags beyond the threshold.
+func (p *EventPublisher) awaitConsumers(ctx context.Context) error {
+	interval := p.config.Backpressure.checkInterval()
+	logged := false
+	for {
+		lagging, err := p.consumersLagging(ctx, interval)
+		if err != nil {
+			// Backpressure is best effort; publish rather than stall on a failed check
+			log.Printf("backpressure: lag check failed: %v", err)
+			return nil
+		}
+		if !lagging {
+			return nil
+		}
+		if !logged {
+			log.Printf("backpressure: consumers lag by more than %d entries, pausing backlog publishing", p.config.Backpressure.MaxLag)
+			logged = true
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(interval):
+		}
+	}
+}
+
+// consumersLagging reports whether a consumer group lags beyond the threshold, checking at most once per interval.
+func (p *EventPublisher) consumersLagging(ctx context.Context, interval time.Duration) (bool, error) {
+	p.lag.mu.Lock()
+	defer p.lag.mu.Unlock()
+
+	if !p.lag.checkedAt.IsZero() && time.Since(p.lag.checkedAt) < interval {
+		return p.lag.lagging, nil
+	}
+
+	streams := p.transactionStreams()
+	if p.config.EntryIDs == EntryIDsBlock {
+		for _, stream := range streams {
+			streams = append(streams, stream+backlogStreamSuffix)
+		}
+	}
+	lagging := false
+	for _, stream := range streams {
+		lag, err := p.maxGroupLag(ctx, stream)
+		if err != nil {
+			return false, err
+		}
+		if lag > p.config.Backpressure.MaxLag {
+			lagging = true
+			break
+		}
+	}
+	p.lag.lagging = lagging
+	p.lag.checkedAt = time.Now()
+	return lagging, nil
+}
+
+// maxGroupLag returns the largest lag of the consumer groups of a stream, from XINFO GROUPS. Redis
+// before 7.0 doesn't report the lag, so the pending entries of the group are used instead.
+func (p *EventPublisher) maxGroupLag(ctx context.Context, stream string) (int64, error) {
+	groups, err := p.client.Do(ctx, "XINFO", "GROUPS", stream).Slice()
+	if err != nil {
+		if err == redis.Nil || strings.Contains(err.Error(), "no such key") {
+			return 0, nil
+		}
+		return 0, err
+	}
+
+	var max int64
+	for _, group := range groups {
+		fields, ok := group.([]interface{})
+		if !ok {
+			continue
+		}
+		var lag, pending int64
+		hasLag := false
+		for i := 0; i+1 < len(fields); i += 2 {
+			name, _ := fields[i].(string)
+			value, ok := fields[i+1].(int64)
+			switch {
+			case name == "lag" && ok:
+				lag, hasLag = value, true
+			case name == "pending" && ok:
+				pending = value
+			}
+		}
+		if !hasLag {
+			lag = pending
+		}
+		if lag > max {
+			max = lag
+		}
+	}
+	return max, nil
+}
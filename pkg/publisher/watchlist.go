@@ -0,0 +1,178 @@
+package publisher
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	redisPkg "github.com/sunbankio/tronevents/pkg/redis"
+	"github.com/sunbankio/tronevents/pkg/scanner"
+)
+
+const defaultWatchlistRefresh = 10
+
+// WatchlistConfig holds the address watchlist. Transactions involving a watched address are copied to
+// the stream of its tenant, or to a stream of its own for addresses without a tenant.
+type WatchlistConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// Key is the Redis hash of the watched addresses and their tenants (default: <prefix>:watchlist).
+	Key string `yaml:"key"`
+	// StreamPrefix names the streams "<stream_prefix>:<tenant>" or "<stream_prefix>:<address>" (default: <prefix>:watch).
+	StreamPrefix string `yaml:"stream_prefix"`
+	// RefreshInterval is the number of seconds the watchlist is cached by the publisher (default 10).
+	RefreshInterval int `yaml:"refresh_interval"`
+	// IncludeContracts also copies the transactions calling a watched contract or whose logs it emits.
+	// By default only the parties of a transaction are matched: owner, recipient, TRC20 sender and
+	// receiver, and internal transaction callers and recipients.
+	IncludeContracts bool `yaml:"include_contracts"`
+}
+
+// Validate checks the watchlist configuration for invalid values.
+func (c *WatchlistConfig) Validate() error {
+	if c.RefreshInterval < 0 {
+		return fmt.Errorf("watchlist refresh_interval must not be negative")
+	}
+	return nil
+}
+
+// ApplyPrefix derives the watchlist key and stream prefix that are not set from the Redis key prefix.
+func (c *WatchlistConfig) ApplyPrefix(prefix string) {
+	if prefix == "" {
+		prefix = redisPkg.DefaultPrefix
+	}
+	if c.Key == "" {
+		c.Key = prefix + ":watchlist"
+	}
+	if c.StreamPrefix == "" {
+		c.StreamPrefix = prefix + ":watch"
+	}
+}
+
+func (c *WatchlistConfig) refreshInterval() time.Duration {
+	if c.RefreshInterval == 0 {
+		return defaultWatchlistRefresh * time.Second
+	}
+	return time.Duration(c.RefreshInterval) * time.Second
+}
+
+// Watchlist manages the watched addresses, stored in a Redis hash of address to tenant.
+// Changes are picked up by running publishers within their refresh interval.
+type Watchlist struct {
+	client *redis.Client
+	key    string
+}
+
+// NewWatchlist creates a Watchlist stored in the given Redis hash.
+func NewWatchlist(client *redis.Client, key string) *Watchlist {
+	return &Watchlist{client: client, key: key}
+}
+
+// Add watches the addresses for a tenant; an empty tenant gives each address a stream of its own.
+// Addresses that are already watched are moved to the tenant.
+func (w *Watchlist) Add(ctx context.Context, tenant string, addresses ...string) error {
+	if len(addresses) == 0 {
+		return nil
+	}
+	values := make([]interface{}, 0, 2*len(addresses))
+	for _, address := range addresses {
+		if address == "" {
+			return fmt.Errorf("watchlist: empty address")
+		}
+		values = append(values, address, tenant)
+	}
+	return w.client.HSet(ctx, w.key, values...).Err()
+}
+
+// Remove stops watching the addresses and returns the number of addresses that were watched.
+func (w *Watchlist) Remove(ctx context.Context, addresses ...string) (int64, error) {
+	if len(addresses) == 0 {
+		return 0, nil
+	}
+	return w.client.HDel(ctx, w.key, addresses...).Result()
+}
+
+// List returns the watched addresses and their tenants.
+func (w *Watchlist) List(ctx context.Context) (map[string]string, error) {
+	return w.client.HGetAll(ctx, w.key).Result()
+}
+
+// watchCache caches the watchlist for the publisher and maps transactions to their watch streams.
+type watchCache struct {
+	watchlist        *Watchlist
+	streamPrefix     string
+	ttl              time.Duration
+	includeContracts bool
+
+	mu       sync.Mutex
+	tenants  map[string]string // address to tenant
+	loadedAt time.Time
+}
+
+func newWatchCache(client *redis.Client, cfg WatchlistConfig) *watchCache {
+	return &watchCache{
+		watchlist:        NewWatchlist(client, cfg.Key),
+		streamPrefix:     cfg.StreamPrefix,
+		ttl:              cfg.refreshInterval(),
+		includeContracts: cfg.IncludeContracts,
+	}
+}
+
+// load returns the watched addresses, reloading them from Redis once the cached copy has expired.
+func (c *watchCache) load(ctx context.Context) (map[string]string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.tenants != nil && time.Since(c.loadedAt) < c.ttl {
+		return c.tenants, nil
+	}
+	tenants, err := c.watchlist.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("watchlist: failed to load %s: %w", c.watchlist.key, err)
+	}
+	c.tenants = tenants
+	c.loadedAt = time.Now()
+	return tenants, nil
+}
+
+// streams returns the watch streams of the watched parties of the transaction, and of its watched
+// contracts when they are included.
+func (c *watchCache) streams(tenants map[string]string, tx *scanner.Transaction) []string {
+	addresses := tx.Parties()
+	if c.includeContracts {
+		addresses = append(addresses, tx.ContractAddress())
+		for _, log := range tx.Logs {
+			addresses = append(addresses, log.Address)
+		}
+	}
+
+	var streams []string
+	for _, address := range addresses {
+		tenant, ok := tenants[address]
+		if !ok {
+			continue
+		}
+		stream := c.stream(address, tenant)
+		if !containsString(streams, stream) {
+			streams = append(streams, stream)
+		}
+	}
+	return streams
+}
+
+func (c *watchCache) stream(address, tenant string) string {
+	if tenant == "" {
+		return c.streamPrefix + ":" + address
+	}
+	return c.streamPrefix + ":" + tenant
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,467 @@
+package publisher
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/sunbankio/tronevents/pkg/codec"
+	"github.com/sunbankio/tronevents/pkg/models"
+	"github.com/sunbankio/tronevents/pkg/queue"
+	redisPkg "github.com/sunbankio/tronevents/pkg/redis"
+	"github.com/sunbankio/tronevents/pkg/scanner"
+)
+
+const (
+	// SinkTypeWebhook POSTs transactions to an HTTP endpoint.
+	SinkTypeWebhook = "webhook"
+
+	// WebhookTimestampHeader carries the unix timestamp included in the signature.
+	WebhookTimestampHeader = "X-Tronevents-Timestamp"
+	// WebhookSignatureHeader carries "sha256=" followed by the hex HMAC-SHA256 of "<timestamp>.<body>".
+	WebhookSignatureHeader = "X-Tronevents-Signature"
+
+	defaultWebhookQueueSize = 100000
+	// webhookPollInterval is how often an empty delivery queue is checked for requests queued by other processes
+	webhookPollInterval = time.Second
+)
+
+// WebhookConfig holds the configuration of the webhook sink.
+type WebhookConfig struct {
+	URL     string            `yaml:"url"`
+	Secret  string            `yaml:"secret"` // HMAC-SHA256 signing secret; bodies are unsigned when empty
+	Headers map[string]string `yaml:"headers"`
+	Timeout int               `yaml:"timeout"` // seconds per request
+	// BatchSize is the maximum number of transactions per request; 0 sends a block in one request.
+	BatchSize int `yaml:"batch_size"`
+	// MaxRetries is the number of retries following queue.RetryDurations; 0 uses the whole schedule.
+	MaxRetries    int      `yaml:"max_retries"`
+	QueueKey      string   `yaml:"queue_key"`       // Redis list of pending deliveries (default: <prefix>:webhook:queue:<url hash>)
+	QueueSize     int      `yaml:"queue_size"`      // pending deliveries before new ones are parked in the dead-letter list
+	DeadLetterKey string   `yaml:"dead_letter_key"` // Redis list of permanently failed deliveries (default: <prefix>:webhook:dead_letter)
+	ContractTypes []string `yaml:"contract_types"`  // only deliver these contract types; empty delivers all
+	Addresses     []string `yaml:"addresses"`       // only deliver transactions involving these addresses; empty delivers all
+}
+
+// Validate checks the webhook configuration for invalid values.
+func (c *WebhookConfig) Validate() error {
+	if c.URL == "" {
+		return fmt.Errorf("webhook: url is required")
+	}
+	if c.MaxRetries > len(queue.RetryDurations) {
+		return fmt.Errorf("webhook: max_retries must be at most %d", len(queue.RetryDurations))
+	}
+	if c.BatchSize < 0 || c.QueueSize < 0 {
+		return fmt.Errorf("webhook: batch_size and queue_size must not be negative")
+	}
+	return nil
+}
+
+// WebhookBatch is the JSON body of a webhook request.
+type WebhookBatch struct {
+	BlockNumber  int64                    `json:"block_number"`
+	BlockHash    string                   `json:"block_hash"`
+	Transactions []models.SafeTransaction `json:"transactions"`
+}
+
+// webhookDelivery is a request body awaiting delivery, stored as-is in the queue and dead-letter lists.
+type webhookDelivery struct {
+	URL         string          `json:"url"`
+	Body        json.RawMessage `json:"body"`
+	ContentType string          `json:"content_type,omitempty"` // application/json when empty
+	Attempts    int             `json:"attempts"`
+	LastError   string          `json:"last_error,omitempty"`
+	FailedAt    time.Time       `json:"failed_at,omitempty"`
+}
+
+// WebhookSink POSTs matching transactions to an HTTP endpoint.
+// Requests are queued in a Redis list of the endpoint, so they survive a restart once the block is
+// processed, and are delivered in order by a background goroutine so a slow or failing endpoint never
+// blocks block processing nor the other endpoints. A request is removed from the queue once delivered,
+// so it may be delivered again after a crash. Deliveries that exhaust their retries or overflow the
+// queue are parked in a Redis dead-letter list and can be replayed with ReplayDeadLetters.
+type WebhookSink struct {
+	client        *redis.Client
+	httpClient    *http.Client
+	config        WebhookConfig
+	contractTypes map[string]bool
+	addresses     map[string]bool
+	retries       []time.Duration
+	// cloudEventsSource switches the bodies to CloudEvents batches when set
+	cloudEventsSource string
+
+	queued    chan struct{} // signals the delivery goroutine that requests were queued
+	stop      context.CancelFunc
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// webhookQueueKey returns the default queue key of an endpoint. The URL is hashed, as it may carry
+// credentials or tokens that must not show up in key names and logs.
+func webhookQueueKey(prefix, url string) string {
+	sum := sha256.Sum256([]byte(url))
+	return prefix + ":webhook:queue:" + hex.EncodeToString(sum[:8])
+}
+
+// NewWebhookSink creates a new WebhookSink and starts delivering its queue, including the requests
+// left by a previous run.
+func NewWebhookSink(cfg WebhookConfig, client *redis.Client) (*WebhookSink, error) {
+	s, err := NewWebhookReplayer(cfg, client)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, stop := context.WithCancel(context.Background())
+	s.stop = stop
+	s.done = make(chan struct{})
+	go s.run(ctx)
+	return s, nil
+}
+
+// NewWebhookReplayer creates a WebhookSink that doesn't deliver its queue, to replay the dead letters
+// while the daemon keeps delivering.
+func NewWebhookReplayer(cfg WebhookConfig, client *redis.Client) (*WebhookSink, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	if cfg.QueueKey == "" {
+		cfg.QueueKey = webhookQueueKey(redisPkg.DefaultPrefix, cfg.URL)
+	}
+	if cfg.DeadLetterKey == "" {
+		cfg.DeadLetterKey = redisPkg.DefaultPrefix + ":webhook:dead_letter"
+	}
+	if cfg.QueueSize == 0 {
+		cfg.QueueSize = defaultWebhookQueueSize
+	}
+	timeout := time.Duration(cfg.Timeout) * time.Second
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	retries := queue.RetryDurations
+	if cfg.MaxRetries > 0 {
+		retries = retries[:cfg.MaxRetries]
+	}
+
+	return &WebhookSink{
+		client:        client,
+		httpClient:    &http.Client{Timeout: timeout},
+		config:        cfg,
+		contractTypes: stringSet(cfg.ContractTypes),
+		addresses:     stringSet(cfg.Addresses),
+		retries:       retries,
+		queued:        make(chan struct{}, 1),
+	}, nil
+}
+
+// SetCloudEvents implements CloudEventsSink. Each request body becomes a JSON array of
+// structured-mode events, sent as application/cloudevents-batch+json.
+func (s *WebhookSink) SetCloudEvents(source string) {
+	s.cloudEventsSource = source
+}
+
+// PublishBlock queues the requests of the matching transactions of a block for delivery, or parks them
+// in the dead-letter list when the queue is full.
+func (s *WebhookSink) PublishBlock(ctx context.Context, block *scanner.Block) error {
+	txs := make([]models.SafeTransaction, 0, len(block.Transactions))
+	for i := range block.Transactions {
+		if s.matches(&block.Transactions[i]) {
+			txs = append(txs, models.ConvertTransaction(block.Transactions[i]))
+		}
+	}
+
+	batchSize := s.config.BatchSize
+	if batchSize == 0 {
+		batchSize = len(txs)
+	}
+	var deliveries []*webhookDelivery
+	for start := 0; start < len(txs); start += batchSize {
+		end := start + batchSize
+		if end > len(txs) {
+			end = len(txs)
+		}
+
+		body, err := s.batchBody(block, txs[start:end])
+		if err != nil {
+			return err
+		}
+
+		delivery := &webhookDelivery{URL: s.config.URL, Body: body}
+		if s.cloudEventsSource != "" {
+			delivery.ContentType = codec.CloudEventsBatchContentType
+		}
+		deliveries = append(deliveries, delivery)
+	}
+	if len(deliveries) == 0 {
+		return nil
+	}
+
+	pending, err := s.client.LLen(ctx, s.config.QueueKey).Result()
+	if err != nil {
+		return fmt.Errorf("webhook: failed to check queue %s: %w", s.config.QueueKey, err)
+	}
+	if pending+int64(len(deliveries)) > int64(s.config.QueueSize) {
+		for _, delivery := range deliveries {
+			delivery.LastError = "delivery queue full"
+		}
+		return s.park(ctx, deliveries...)
+	}
+
+	entries := make([]interface{}, len(deliveries))
+	for i, delivery := range deliveries {
+		entry, err := json.Marshal(delivery)
+		if err != nil {
+			return err
+		}
+		entries[i] = entry
+	}
+	if err := s.client.RPush(ctx, s.config.QueueKey, entries...).Err(); err != nil {
+		return fmt.Errorf("webhook: failed to queue block %d in %s: %w", block.Number, s.config.QueueKey, err)
+	}
+	select {
+	case s.queued <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// batchBody returns the request body of a batch of transactions of a block.
+func (s *WebhookSink) batchBody(block *scanner.Block, txs []models.SafeTransaction) ([]byte, error) {
+	if s.cloudEventsSource == "" {
+		return json.Marshal(WebhookBatch{
+			BlockNumber:  block.Number,
+			BlockHash:    block.Hash,
+			Transactions: txs,
+		})
+	}
+
+	events := make([]*codec.CloudEvent, len(txs))
+	for i := range txs {
+		event, err := codec.TransactionEvent(&txs[i], s.cloudEventsSource)
+		if err != nil {
+			return nil, err
+		}
+		events[i] = event
+	}
+	return json.Marshal(events)
+}
+
+// Close stops the delivery goroutine. Undelivered requests stay queued for the next run.
+func (s *WebhookSink) Close() error {
+	s.closeOnce.Do(func() {
+		if s.stop != nil {
+			s.stop()
+			<-s.done
+		}
+	})
+	return nil
+}
+
+// ReplayDeadLetters attempts once to deliver every parked request for this sink's URL,
+// removing the ones that succeed. It returns the number of delivered requests.
+func (s *WebhookSink) ReplayDeadLetters(ctx context.Context) (int, error) {
+	entries, err := s.client.LRange(ctx, s.config.DeadLetterKey, 0, -1).Result()
+	if err != nil {
+		return 0, err
+	}
+
+	replayed := 0
+	for _, entry := range entries {
+		var delivery webhookDelivery
+		if err := json.Unmarshal([]byte(entry), &delivery); err != nil {
+			log.Printf("webhook: skipping malformed dead letter: %v", err)
+			continue
+		}
+		if delivery.URL != s.config.URL {
+			continue
+		}
+
+		if err := s.deliver(ctx, &delivery); err != nil {
+			log.Printf("webhook: replay to %s failed: %v", delivery.URL, err)
+			continue
+		}
+		if err := s.client.LRem(ctx, s.config.DeadLetterKey, 1, entry).Err(); err != nil {
+			return replayed, err
+		}
+		replayed++
+	}
+	return replayed, nil
+}
+
+// run delivers the queued requests in order until ctx is cancelled, retrying each one before moving
+// to the next. The head of the queue is only removed once delivered or parked.
+func (s *WebhookSink) run(ctx context.Context) {
+	defer close(s.done)
+
+	for ctx.Err() == nil {
+		entry, err := s.client.LIndex(ctx, s.config.QueueKey, 0).Result()
+		if err == nil {
+			s.deliverWithRetries(ctx, entry)
+			continue
+		}
+		if err != redis.Nil && ctx.Err() == nil {
+			log.Printf("webhook: failed to read queue %s: %v", s.config.QueueKey, err)
+		}
+
+		select {
+		case <-ctx.Done():
+		case <-s.queued:
+		case <-time.After(webhookPollInterval):
+		}
+	}
+}
+
+// deliverWithRetries delivers a queued entry, retrying it until it succeeds, exhausts its retries or
+// ctx is cancelled, in which case it stays at the head of the queue.
+func (s *WebhookSink) deliverWithRetries(ctx context.Context, entry string) {
+	var delivery webhookDelivery
+	if err := json.Unmarshal([]byte(entry), &delivery); err != nil {
+		log.Printf("webhook: dropping malformed queued request: %v", err)
+		s.dequeue(entry, nil)
+		return
+	}
+
+	for {
+		err := s.deliver(ctx, &delivery)
+		if ctx.Err() != nil {
+			return
+		}
+		delivery.Attempts++
+		if err == nil {
+			s.dequeue(entry, nil)
+			return
+		}
+		delivery.LastError = err.Error()
+
+		if delivery.Attempts > len(s.retries) {
+			log.Printf("webhook: giving up on delivery to %s after %d attempts: %v", delivery.URL, delivery.Attempts, err)
+			s.dequeue(entry, &delivery)
+			return
+		}
+
+		backoff := s.retries[delivery.Attempts-1]
+		log.Printf("webhook: delivery to %s failed, retrying in %v: %v", delivery.URL, backoff, err)
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// dequeue removes a delivered entry from the queue, parking the failed delivery in the same step if
+// not nil. A failure is only logged: the entry is delivered again.
+func (s *WebhookSink) dequeue(entry string, failed *webhookDelivery) {
+	// Not bound to the delivery goroutine, so a delivered request is dequeued even during Close
+	ctx := context.Background()
+
+	pipe := s.client.TxPipeline()
+	if failed != nil {
+		failed.FailedAt = time.Now()
+		parked, err := json.Marshal(failed)
+		if err != nil {
+			log.Printf("webhook: %v", err)
+			return
+		}
+		pipe.RPush(ctx, s.config.DeadLetterKey, parked)
+	}
+	pipe.LRem(ctx, s.config.QueueKey, 1, entry)
+	if _, err := pipe.Exec(ctx); err != nil {
+		log.Printf("webhook: failed to dequeue request from %s: %v", s.config.QueueKey, err)
+	}
+}
+
+// deliver POSTs a signed body, treating any non-2xx response as a failure.
+func (s *WebhookSink) deliver(ctx context.Context, delivery *webhookDelivery) error {
+	body := delivery.Body
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.config.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	contentType := delivery.ContentType
+	if contentType == "" {
+		contentType = "application/json"
+	}
+	req.Header.Set("Content-Type", contentType)
+	for key, value := range s.config.Headers {
+		req.Header.Set(key, value)
+	}
+	if s.config.Secret != "" {
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		req.Header.Set(WebhookTimestampHeader, timestamp)
+		req.Header.Set(WebhookSignatureHeader, "sha256="+SignWebhook(s.config.Secret, timestamp, body))
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return nil
+}
+
+// park appends failed deliveries to the dead-letter list.
+func (s *WebhookSink) park(ctx context.Context, deliveries ...*webhookDelivery) error {
+	entries := make([]interface{}, len(deliveries))
+	for i, delivery := range deliveries {
+		delivery.FailedAt = time.Now()
+		entry, err := json.Marshal(delivery)
+		if err != nil {
+			return err
+		}
+		entries[i] = entry
+	}
+	if err := s.client.RPush(ctx, s.config.DeadLetterKey, entries...).Err(); err != nil {
+		return fmt.Errorf("webhook: failed to park delivery in %s: %w", s.config.DeadLetterKey, err)
+	}
+	return nil
+}
+
+// matches reports whether a transaction passes the contract type and address filters.
+func (s *WebhookSink) matches(tx *scanner.Transaction) bool {
+	if len(s.contractTypes) > 0 && (tx.Contract == nil || !s.contractTypes[tx.Contract.Type]) {
+		return false
+	}
+	if len(s.addresses) > 0 {
+		for _, address := range tx.Addresses() {
+			if s.addresses[address] {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// SignWebhook returns the hex HMAC-SHA256 of "<timestamp>.<body>" that receivers should compare
+// against the signature header.
+func SignWebhook(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func stringSet(values []string) map[string]bool {
+	set := make(map[string]bool, len(values))
+	for _, value := range values {
+		set[value] = true
+	}
+	return set
+}
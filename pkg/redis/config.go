@@ -1,5 +1,8 @@
 package redis
 
+// DefaultPrefix is the key prefix used when none is configured.
+const DefaultPrefix = "tron"
+
 // Config holds the configuration for the Redis client.
 type Config struct {
 	Addr     string `yaml:"addr"`
@@ -7,3 +10,11 @@ type Config struct {
 	DB       int    `yaml:"db"`
 	Prefix   string `yaml:"prefix"`
 }
+
+// KeyPrefix returns the configured key prefix, or DefaultPrefix.
+func (c *Config) KeyPrefix() string {
+	if c.Prefix == "" {
+		return DefaultPrefix
+	}
+	return c.Prefix
+}
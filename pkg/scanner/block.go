@@ -0,0 +1,73 @@
+package scanner
+
+import (
+	"time"
+)
+
+// Block represents a scanned TRON block together with its parsed transactions
+type Block struct {
+	Number       int64         `json:"number"`
+	Hash         string        `json:"hash"`
+	Timestamp    time.Time     `json:"timestamp"`
+	Producer     string        `json:"producer,omitempty"`
+	Transactions []Transaction `json:"transactions"`
+	// TransactionCount is the number of transactions of the block, which sinks given a filtered
+	// copy of the block don't see all of
+	TransactionCount int `json:"transaction_count"`
+}
+
+// BlockStats represents aggregate statistics for a single block
+type BlockStats struct {
+	BlockNumber      int64            `json:"block_number"`
+	BlockHash        string           `json:"block_hash"`
+	BlockTimestamp   time.Time        `json:"block_timestamp"`
+	Producer         string           `json:"producer,omitempty"`
+	TransactionCount int              `json:"transaction_count"`
+	ContractTypes    map[string]int64 `json:"contract_types"`
+	SuccessCount     int64            `json:"success_count"`
+	FailureCount     int64            `json:"failure_count"`
+	EnergyUsed       int64            `json:"energy_used"`
+	EnergyBurned     int64            `json:"energy_burned"` // TRX (in sun) burned to pay for energy
+	BandwidthUsed    int64            `json:"bandwidth_used"`
+	TRC20Transfers   int64            `json:"trc20_transfers"`
+}
+
+// Stats aggregates the transactions of the block into a BlockStats summary
+func (b *Block) Stats() BlockStats {
+	stats := BlockStats{
+		BlockNumber:      b.Number,
+		BlockHash:        b.Hash,
+		BlockTimestamp:   b.Timestamp,
+		Producer:         b.Producer,
+		TransactionCount: len(b.Transactions),
+		ContractTypes:    make(map[string]int64),
+	}
+
+	for i := range b.Transactions {
+		tx := &b.Transactions[i]
+
+		if tx.Contract != nil {
+			stats.ContractTypes[tx.Contract.Type]++
+		}
+
+		if tx.IsSuccess() {
+			stats.SuccessCount++
+		} else {
+			stats.FailureCount++
+		}
+
+		if tx.Receipt != nil {
+			stats.EnergyUsed += tx.Receipt.EnergyUsageTotal
+			stats.EnergyBurned += tx.Receipt.EnergyFee
+			stats.BandwidthUsed += tx.Receipt.NetUsage
+		}
+
+		for _, log := range tx.Logs {
+			if log.IsTRC20Transfer() {
+				stats.TRC20Transfers++
+			}
+		}
+	}
+
+	return stats
+}
@@ -46,6 +46,52 @@ type TransferAssetContract struct {
 	Amount       int64  `json:"amount"`
 }
 
+// AssetIssueContract represents a TRC10 asset issuance transaction
+type AssetIssueContract struct {
+	OwnerAddress            string         `json:"owner_address"`
+	Name                    string         `json:"name"`
+	Abbr                    string         `json:"abbr,omitempty"`
+	TotalSupply             int64          `json:"total_supply"`
+	FrozenSupply            []FrozenSupply `json:"frozen_supply,omitempty"`
+	TrxNum                  int32          `json:"trx_num"`
+	Precision               int32          `json:"precision,omitempty"`
+	Num                     int32          `json:"num"`
+	StartTime               int64          `json:"start_time"`
+	EndTime                 int64          `json:"end_time"`
+	Description             string         `json:"description,omitempty"`
+	Url                     string         `json:"url,omitempty"`
+	FreeAssetNetLimit       int64          `json:"free_asset_net_limit,omitempty"`
+	PublicFreeAssetNetLimit int64          `json:"public_free_asset_net_limit,omitempty"`
+}
+
+// FrozenSupply represents the part of an issued asset frozen for a number of days
+type FrozenSupply struct {
+	FrozenAmount int64 `json:"frozen_amount"`
+	FrozenDays   int64 `json:"frozen_days"`
+}
+
+// ParticipateAssetIssueContract represents a purchase of a TRC10 asset from its issuer
+type ParticipateAssetIssueContract struct {
+	OwnerAddress string `json:"owner_address"`
+	ToAddress    string `json:"to_address"`
+	AssetName    string `json:"asset_name"`
+	Amount       int64  `json:"amount"`
+}
+
+// UnfreezeAssetContract represents an unfreeze of the frozen supply of an issued asset
+type UnfreezeAssetContract struct {
+	OwnerAddress string `json:"owner_address"`
+}
+
+// UpdateAssetContract represents an update of an issued asset
+type UpdateAssetContract struct {
+	OwnerAddress   string `json:"owner_address"`
+	Description    string `json:"description,omitempty"`
+	Url            string `json:"url,omitempty"`
+	NewLimit       int64  `json:"new_limit"`
+	NewPublicLimit int64  `json:"new_public_limit"`
+}
+
 // Balance contracts
 // DelegateResourceContract represents a resource delegation transaction
 type DelegateResourceContract struct {
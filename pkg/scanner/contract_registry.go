@@ -0,0 +1,48 @@
+package scanner
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/kslamph/tronlib/pb/core"
+	"google.golang.org/protobuf/proto"
+)
+
+// ContractParser converts the parameter of a raw contract into its structured representation.
+// The returned value is published as Contract.Parameter; returning an error leaves the contract unparsed.
+type ContractParser func(contract *core.Transaction_Contract) (interface{}, error)
+
+var (
+	contractParsersMu sync.RWMutex
+	contractParsers   = make(map[core.Transaction_Contract_ContractType]ContractParser)
+)
+
+// RegisterContractParser registers the parser for a contract type, replacing any existing parser.
+// It allows library users to add or override parsing for a contract type without forking the scanner.
+func RegisterContractParser(contractType core.Transaction_Contract_ContractType, parser ContractParser) {
+	contractParsersMu.Lock()
+	defer contractParsersMu.Unlock()
+
+	if parser == nil {
+		delete(contractParsers, contractType)
+		return
+	}
+	contractParsers[contractType] = parser
+}
+
+// lookupContractParser returns the parser registered for a contract type
+func lookupContractParser(contractType core.Transaction_Contract_ContractType) (ContractParser, bool) {
+	contractParsersMu.RLock()
+	defer contractParsersMu.RUnlock()
+
+	parser, ok := contractParsers[contractType]
+	return parser, ok
+}
+
+// unmarshalParameter extracts the contract parameter from the Any type into msg
+func unmarshalParameter(contract *core.Transaction_Contract, msg proto.Message) error {
+	if contract.Parameter == nil {
+		return fmt.Errorf("contract %s has no parameter", contract.Type.String())
+	}
+	return contract.Parameter.UnmarshalTo(msg)
+}
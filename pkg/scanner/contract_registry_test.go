@@ -0,0 +1,44 @@
+package scanner
+
+import (
+	"testing"
+
+	"github.com/kslamph/tronlib/pb/core"
+)
+
+// unparsedContractTypes are the contract types deliberately left without a parser.
+var unparsedContractTypes = map[core.Transaction_Contract_ContractType]string{
+	// GetContract is only used by the node API to query contracts and never appears in a block
+	core.Transaction_Contract_GetContract: "not an on-chain transaction",
+}
+
+func TestEveryContractTypeHasParser(t *testing.T) {
+	if len(core.Transaction_Contract_ContractType_name) == 0 {
+		t.Fatal("no contract types defined")
+	}
+	for value, name := range core.Transaction_Contract_ContractType_name {
+		contractType := core.Transaction_Contract_ContractType(value)
+		_, registered := lookupContractParser(contractType)
+		reason, excepted := unparsedContractTypes[contractType]
+		switch {
+		case excepted && registered:
+			t.Errorf("%s (%d) has a parser but is listed as unparsed: %s", name, value, reason)
+		case !excepted && !registered:
+			t.Errorf("%s (%d) has no registered parser", name, value)
+		}
+	}
+}
+
+func TestUnregisteredContractKeepsType(t *testing.T) {
+	contractType := core.Transaction_Contract_GetContract
+	contract := parseContract(&core.Transaction_Contract{Type: contractType, PermissionId: 2})
+	if contract.Type != contractType.String() {
+		t.Errorf("Type = %q, want %q", contract.Type, contractType.String())
+	}
+	if contract.Parameter != nil {
+		t.Errorf("Parameter = %v, want nil", contract.Parameter)
+	}
+	if contract.PermissionID != 2 {
+		t.Errorf("PermissionID = %d, want 2", contract.PermissionID)
+	}
+}
@@ -72,6 +72,7 @@ func parseTransactionWithInfo(tx *api.TransactionExtention, txInfo *core.Transac
 			transaction.Receipt.EnergyUsageTotal = txInfo.Receipt.EnergyUsageTotal
 			transaction.Receipt.NetUsage = txInfo.Receipt.NetUsage
 			transaction.Receipt.NetFee = txInfo.Receipt.NetFee
+			transaction.Receipt.Result = txInfo.Receipt.Result.String()
 		}
 
 		// Add logs from TransactionInfo (these are typically more complete)
@@ -96,9 +97,10 @@ func parseTransactionWithInfo(tx *api.TransactionExtention, txInfo *core.Transac
 						logInfo.Inputs = make([]EventInput, len(decodedEvent.Parameters))
 						for i, param := range decodedEvent.Parameters {
 							logInfo.Inputs[i] = EventInput{
-								Name:  param.Name,
-								Type:  param.Type,
-								Value: param.Value,
+								Name:    param.Name,
+								Type:    param.Type,
+								Value:   param.Value,
+								Indexed: param.Indexed,
 							}
 						}
 					}
@@ -117,6 +119,24 @@ func parseTransactionWithInfo(tx *api.TransactionExtention, txInfo *core.Transac
 				}
 			}
 		}
+
+		// Add internal transactions, keeping the TRX call value
+		if len(txInfo.InternalTransactions) > 0 {
+			transaction.InternalTransactions = make([]InternalTransaction, 0, len(txInfo.InternalTransactions))
+			for _, internal := range txInfo.InternalTransactions {
+				internalTx := InternalTransaction{
+					CallerAddress: byteAddrToString(internal.CallerAddress),
+					ToAddress:     byteAddrToString(internal.TransferToAddress),
+					Rejected:      internal.Rejected,
+				}
+				for _, callValue := range internal.CallValueInfo {
+					if callValue.TokenId == "" {
+						internalTx.CallValue += callValue.CallValue
+					}
+				}
+				transaction.InternalTransactions = append(transaction.InternalTransactions, internalTx)
+			}
+		}
 	}
 
 	return transaction
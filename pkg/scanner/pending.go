@@ -0,0 +1,51 @@
+package scanner
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/kslamph/tronlib/pb/api"
+)
+
+// PendingTransactionIDs returns the IDs of the transactions currently in the node's pending pool
+func (s *Scanner) PendingTransactionIDs(ctx context.Context) ([]string, error) {
+	conn, err := s.tronclient.GetConnection(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer s.tronclient.ReturnConnection(conn)
+
+	list, err := api.NewWalletClient(conn).GetTransactionListFromPending(ctx, &api.EmptyMessage{})
+	if err != nil {
+		return nil, err
+	}
+	return list.TxId, nil
+}
+
+// PendingTransaction fetches and parses a transaction from the node's pending pool
+func (s *Scanner) PendingTransaction(ctx context.Context, txID string) (Transaction, error) {
+	txIDBytes, err := hex.DecodeString(txID)
+	if err != nil {
+		return Transaction{}, fmt.Errorf("invalid transaction id %q: %v", txID, err)
+	}
+
+	conn, err := s.tronclient.GetConnection(ctx)
+	if err != nil {
+		return Transaction{}, err
+	}
+	defer s.tronclient.ReturnConnection(conn)
+
+	tx, err := api.NewWalletClient(conn).GetTransactionFromPending(ctx, &api.BytesMessage{Value: txIDBytes})
+	if err != nil {
+		return Transaction{}, err
+	}
+	if tx == nil || tx.RawData == nil {
+		return Transaction{}, fmt.Errorf("pending transaction %s not found", txID)
+	}
+
+	return parseTransaction(&api.TransactionExtention{
+		Transaction: tx,
+		Txid:        txIDBytes,
+	}), nil
+}
@@ -13,6 +13,8 @@ import (
 
 type Scanner struct {
 	tronclient *client.Client
+	includeRaw bool
+	tokens     *tokenMetadataCache
 }
 
 func NewScanner(nodeAddress string, timeout int, poolSize int, maxPoolSize int) (*Scanner, error) {
@@ -30,40 +32,56 @@ func NewScanner(nodeAddress string, timeout int, poolSize int, maxPoolSize int)
 	}, nil
 }
 
+// SetIncludeRaw enables capturing the serialized Transaction and TransactionInfo protobufs
+// on each parsed transaction, for lossless archiving
+func (s *Scanner) SetIncludeRaw(include bool) {
+	s.includeRaw = include
+}
+
 func (s *Scanner) Close() {
 	s.tronclient.Close()
 }
 
 func (s *Scanner) Scan(ctx context.Context, blockNumber int64) (int64, time.Time, []Transaction, error) {
+	block, err := s.ScanBlock(ctx, blockNumber)
+	if err != nil {
+		return 0, time.Time{}, nil, err
+	}
+	return block.Number, block.Timestamp, block.Transactions, nil
+}
+
+// ScanBlock scans the given block (or the latest block if blockNumber is 0) and returns
+// the block header details together with its parsed transactions
+func (s *Scanner) ScanBlock(ctx context.Context, blockNumber int64) (*Block, error) {
 	var block *api.BlockExtention
 
 	if blockNumber > 0 {
 		var err error
 		block, err = s.getBlockByNumber(ctx, blockNumber)
 		if err != nil {
-			return 0, time.Time{}, nil, err
+			return nil, err
 		}
 		// Check if block is nil (block doesn't exist)
 		if block == nil {
-			return 0, time.Time{}, nil, fmt.Errorf("block %d is nil", blockNumber)
+			return nil, fmt.Errorf("block %d is nil", blockNumber)
 		}
 	} else {
 		var err error
 		// Get the latest block
 		block, err = s.tronclient.Network().GetNowBlock(ctx)
 		if err != nil {
-			return 0, time.Time{}, nil, err
+			return nil, err
 		}
 		// Check if block is nil (shouldn't happen for latest block, but be safe)
 		if block == nil {
-			return 0, time.Time{}, nil, fmt.Errorf("current block is nil")
+			return nil, fmt.Errorf("current block is nil")
 		}
 		blockNumber = block.BlockHeader.RawData.Number
 	}
 
 	// Additional safety check for block header
 	if block.BlockHeader == nil || block.BlockHeader.RawData == nil {
-		return 0, time.Time{}, nil, fmt.Errorf("block %d does not exist", blockNumber)
+		return nil, fmt.Errorf("block %d does not exist", blockNumber)
 	}
 
 	blockTime := time.Unix(0, block.BlockHeader.RawData.Timestamp*int64(time.Millisecond))
@@ -71,7 +89,7 @@ func (s *Scanner) Scan(ctx context.Context, blockNumber int64) (int64, time.Time
 	// Get transaction info - assuming it always exists for block transactions
 	txInfoList, err := s.getTransactionInfoByNumber(ctx, blockNumber)
 	if err != nil {
-		return 0, time.Time{}, nil, err
+		return nil, err
 	}
 
 	// Create a map of transaction info by transaction ID for easy lookup
@@ -90,15 +108,43 @@ func (s *Scanner) Scan(ctx context.Context, blockNumber int64) (int64, time.Time
 		if txInfo, exists := txInfoMap[txID]; exists {
 			// Parse the transaction with the available info
 			transaction := parseTransactionWithInfo(tx, txInfo)
+			transaction.Index = len(transactions)
+			if s.includeRaw {
+				if transaction.Raw, err = rawData(tx, txInfo); err != nil {
+					return nil, err
+				}
+			}
 			transactions = append(transactions, transaction)
 		} else {
 			// This should not happen if txinfo always exists, but handle gracefully
 			transaction := parseTransaction(tx)
+			transaction.Index = len(transactions)
+			if s.includeRaw {
+				if transaction.Raw, err = rawData(tx, nil); err != nil {
+					return nil, err
+				}
+			}
 			transactions = append(transactions, transaction)
 		}
 	}
 
-	return blockNumber, blockTime, transactions, nil
+	if s.tokens != nil {
+		s.enrichTokenTransfers(ctx, transactions)
+	}
+
+	result := &Block{
+		Number:       blockNumber,
+		Hash:         hex.EncodeToString(block.Blockid),
+		Timestamp:    blockTime,
+		Transactions: transactions,
+
+		TransactionCount: len(transactions),
+	}
+	if len(block.BlockHeader.RawData.WitnessAddress) > 0 {
+		result.Producer = byteAddrToString(block.BlockHeader.RawData.WitnessAddress)
+	}
+
+	return result, nil
 }
 
 func (s *Scanner) getBlockByNumber(ctx context.Context, blockNumber int64) (*api.BlockExtention, error) {
@@ -108,4 +154,3 @@ func (s *Scanner) getBlockByNumber(ctx context.Context, blockNumber int64) (*api
 func (s *Scanner) getTransactionInfoByNumber(ctx context.Context, blockNumber int64) (*api.TransactionInfoList, error) {
 	return s.tronclient.Network().GetTransactionInfoByBlockNum(ctx, blockNumber)
 }
-
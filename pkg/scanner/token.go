@@ -0,0 +1,260 @@
+package scanner
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kslamph/tronlib/pb/api"
+	"github.com/kslamph/tronlib/pb/core"
+	"github.com/kslamph/tronlib/pkg/types"
+	"gopkg.in/yaml.v2"
+)
+
+// Function selectors of the TRC20 metadata getters
+var (
+	selectorName     = mustDecodeHex("06fdde03") // name()
+	selectorSymbol   = mustDecodeHex("95d89b41") // symbol()
+	selectorDecimals = mustDecodeHex("313ce567") // decimals()
+)
+
+// maxTokenDecimals is the largest number of decimals whose scale, 10^decimals, fits in a uint256
+const maxTokenDecimals = 77
+
+// tokenMetadataRetry is how long a token whose metadata failed to fetch is left without metadata
+// before fetching it again, so an unreachable node doesn't slow down every transfer of the token.
+const tokenMetadataRetry = time.Minute
+
+// TokenMetadata holds the metadata of a TRC20 token
+type TokenMetadata struct {
+	Name     string `yaml:"name" json:"name"`
+	Symbol   string `yaml:"symbol" json:"symbol"`
+	Decimals int    `yaml:"decimals" json:"decimals"`
+}
+
+// TokenInfo represents the token metadata attached to a decoded token transfer
+type TokenInfo struct {
+	Name     string `json:"name,omitempty"`
+	Symbol   string `json:"symbol,omitempty"`
+	Decimals int    `json:"decimals"`
+	Amount   string `json:"amount,omitempty"` // Transfer value formatted with the token decimals
+}
+
+// tokenMetadataCache caches token metadata by contract address.
+// A nil entry records a contract that does not expose TRC20 metadata.
+type tokenMetadataCache struct {
+	mu     sync.RWMutex
+	tokens map[string]*TokenMetadata
+	failed map[string]time.Time // time after which the metadata of a token is fetched again
+}
+
+// EnableTokenMetadata enables enrichment of TRC20 transfers with token metadata.
+// Metadata is fetched via constant calls the first time a token contract is seen,
+// unless it is defined in the optional YAML overrides file (address -> name, symbol, decimals).
+func (s *Scanner) EnableTokenMetadata(overridesPath string) error {
+	cache := &tokenMetadataCache{
+		tokens: make(map[string]*TokenMetadata),
+		failed: make(map[string]time.Time),
+	}
+
+	if overridesPath != "" {
+		data, err := os.ReadFile(overridesPath)
+		if err != nil {
+			return err
+		}
+		overrides := make(map[string]TokenMetadata)
+		if err := yaml.Unmarshal(data, &overrides); err != nil {
+			return fmt.Errorf("failed to parse token metadata file %s: %v", overridesPath, err)
+		}
+		for address, metadata := range overrides {
+			metadata := metadata
+			if metadata.Decimals < 0 || metadata.Decimals > maxTokenDecimals {
+				return fmt.Errorf("invalid decimals %d for token %s in %s", metadata.Decimals, address, overridesPath)
+			}
+			cache.tokens[address] = &metadata
+		}
+	}
+
+	s.tokens = cache
+	return nil
+}
+
+// enrichTokenTransfers attaches token metadata and formatted amounts to the TRC20 transfers of the transactions
+func (s *Scanner) enrichTokenTransfers(ctx context.Context, transactions []Transaction) {
+	for i := range transactions {
+		for j := range transactions[i].Logs {
+			log := &transactions[i].Logs[j]
+			if !log.IsTRC20Transfer() || log.Address == "" {
+				continue
+			}
+
+			metadata := s.tokens.lookup(ctx, log.Address, s.fetchTokenMetadata)
+			if metadata == nil {
+				continue
+			}
+
+			log.Token = &TokenInfo{
+				Name:     metadata.Name,
+				Symbol:   metadata.Symbol,
+				Decimals: metadata.Decimals,
+				Amount:   formatTokenAmount(log.Inputs[2].Value, metadata.Decimals),
+			}
+		}
+	}
+}
+
+// lookup returns the cached metadata of a token, fetching it on first use. A failed fetch leaves the
+// token without metadata for tokenMetadataRetry, then it is fetched again.
+func (c *tokenMetadataCache) lookup(ctx context.Context, address string, fetch func(context.Context, string) (*TokenMetadata, error)) *TokenMetadata {
+	c.mu.RLock()
+	metadata, ok := c.tokens[address]
+	retryAt, failed := c.failed[address]
+	c.mu.RUnlock()
+	if ok {
+		return metadata
+	}
+	if failed && time.Now().Before(retryAt) {
+		return nil
+	}
+
+	metadata, err := fetch(ctx, address)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err != nil {
+		c.failed[address] = time.Now().Add(tokenMetadataRetry)
+		return nil
+	}
+	delete(c.failed, address)
+	c.tokens[address] = metadata
+	return metadata
+}
+
+// fetchTokenMetadata calls name(), symbol() and decimals() on the token contract.
+// It returns a nil metadata without error if the contract does not implement decimals() or returns
+// more than maxTokenDecimals, and fails if any of the calls can't be made.
+func (s *Scanner) fetchTokenMetadata(ctx context.Context, address string) (*TokenMetadata, error) {
+	contractAddress, err := types.NewAddressFromBase58(address)
+	if err != nil {
+		return nil, err
+	}
+
+	decimalsResult, ok, err := s.callConstant(ctx, contractAddress.Bytes(), selectorDecimals)
+	if err != nil {
+		return nil, err
+	}
+	if !ok || len(decimalsResult) < 32 {
+		return nil, nil
+	}
+	decimals := new(big.Int).SetBytes(decimalsResult[:32])
+	if !decimals.IsInt64() || decimals.Int64() > maxTokenDecimals {
+		return nil, nil
+	}
+
+	metadata := &TokenMetadata{
+		Decimals: int(decimals.Int64()),
+	}
+
+	// name() and symbol() are optional in TRC20
+	result, ok, err := s.callConstant(ctx, contractAddress.Bytes(), selectorName)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		metadata.Name = decodeABIString(result)
+	}
+	result, ok, err = s.callConstant(ctx, contractAddress.Bytes(), selectorSymbol)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		metadata.Symbol = decodeABIString(result)
+	}
+
+	return metadata, nil
+}
+
+// callConstant executes a constant (read-only) call on a contract.
+// ok is false if the call was executed but reverted or returned nothing.
+func (s *Scanner) callConstant(ctx context.Context, contractAddress []byte, data []byte) ([]byte, bool, error) {
+	conn, err := s.tronclient.GetConnection(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	defer s.tronclient.ReturnConnection(conn)
+
+	result, err := api.NewWalletClient(conn).TriggerConstantContract(ctx, &core.TriggerSmartContract{
+		OwnerAddress:    contractAddress,
+		ContractAddress: contractAddress,
+		Data:            data,
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	if result == nil || (result.Result != nil && !result.Result.Result) || len(result.ConstantResult) == 0 {
+		return nil, false, nil
+	}
+
+	return result.ConstantResult[0], true, nil
+}
+
+// decodeABIString decodes an ABI-encoded string return value, falling back to bytes32
+func decodeABIString(data []byte) string {
+	if len(data) >= 64 {
+		// Bounds are compared as remaining lengths so huge offsets and lengths can't overflow
+		size := uint64(len(data))
+		offset := new(big.Int).SetBytes(data[:32])
+		if offset.IsUint64() && offset.Uint64() <= size-32 {
+			start := offset.Uint64()
+			length := new(big.Int).SetBytes(data[start : start+32])
+			if length.IsUint64() && length.Uint64() <= size-start-32 {
+				return string(data[start+32 : start+32+length.Uint64()])
+			}
+		}
+	}
+	if len(data) >= 32 {
+		return strings.TrimRight(string(data[:32]), "\x00")
+	}
+	return ""
+}
+
+// formatTokenAmount formats a raw integer token value with the given number of decimals.
+// It returns an empty string for a value that isn't an integer or decimals beyond maxTokenDecimals.
+func formatTokenAmount(value interface{}, decimals int) string {
+	amount, ok := new(big.Int).SetString(fmt.Sprint(value), 10)
+	if !ok || decimals > maxTokenDecimals {
+		return ""
+	}
+	if decimals <= 0 {
+		return amount.String()
+	}
+
+	negative := amount.Sign() < 0
+	digits := new(big.Int).Abs(amount).String()
+	if len(digits) <= decimals {
+		digits = strings.Repeat("0", decimals-len(digits)+1) + digits
+	}
+
+	integer, fraction := digits[:len(digits)-decimals], strings.TrimRight(digits[len(digits)-decimals:], "0")
+	formatted := integer
+	if fraction != "" {
+		formatted += "." + fraction
+	}
+	if negative {
+		formatted = "-" + formatted
+	}
+	return formatted
+}
+
+func mustDecodeHex(s string) []byte {
+	data, err := hex.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+	return data
+}
@@ -0,0 +1,149 @@
+package scanner
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFormatTokenAmount(t *testing.T) {
+	huge, _ := new(big.Int).SetString(strings.Repeat("9", 78), 10)
+	tests := []struct {
+		name     string
+		value    interface{}
+		decimals int
+		want     string
+	}{
+		{"zero decimals", "1500", 0, "1500"},
+		{"negative decimals", "1500", -3, "1500"},
+		{"whole amount", "1000000", 6, "1"},
+		{"fraction", "1500000", 6, "1.5"},
+		{"smaller than one", "25", 6, "0.000025"},
+		{"exact decimals length", "123456", 6, "0.123456"},
+		{"zero", "0", 18, "0"},
+		{"negative amount", "-1500000", 6, "-1.5"},
+		{"big.Int value", big.NewInt(123450000), 4, "12345"},
+		{"int value", 42, 1, "4.2"},
+		{"uint256 value", huge, 77, "9." + strings.Repeat("9", 77)},
+		{"max decimals", "1", maxTokenDecimals, "0." + strings.Repeat("0", maxTokenDecimals-1) + "1"},
+		{"decimals beyond uint256", "1", maxTokenDecimals + 1, ""},
+		{"huge decimals", "1", 1 << 40, ""},
+		{"not an integer", "1.5", 6, ""},
+		{"leading zero", "0100", 2, "1"},
+		{"hexadecimal", "0x10", 0, ""},
+		{"underscores", "1_000", 0, ""},
+		{"nil value", nil, 6, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := formatTokenAmount(tt.value, tt.decimals); got != tt.want {
+				t.Errorf("formatTokenAmount(%v, %d) = %q, want %q", tt.value, tt.decimals, got, tt.want)
+			}
+		})
+	}
+}
+
+// abiWord returns a 32-byte ABI word holding n
+func abiWord(n *big.Int) []byte {
+	return n.FillBytes(make([]byte, 32))
+}
+
+// abiString encodes s as a dynamic ABI string return value
+func abiString(s string) []byte {
+	data := append(abiWord(big.NewInt(32)), abiWord(big.NewInt(int64(len(s))))...)
+	padded := make([]byte, (len(s)+31)/32*32)
+	copy(padded, s)
+	return append(data, padded...)
+}
+
+func TestDecodeABIString(t *testing.T) {
+	maxUint256 := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(1))
+	maxInt64 := big.NewInt(1<<63 - 1)
+
+	bytes32 := make([]byte, 32)
+	copy(bytes32, "MKR")
+
+	// Malformed strings fall back to decoding the first word as bytes32
+	tests := []struct {
+		name string
+		data []byte
+		want string
+	}{
+		{"string", abiString("Tether USD"), "Tether USD"},
+		{"empty string", abiString(""), ""},
+		{"long string", abiString(strings.Repeat("x", 70)), strings.Repeat("x", 70)},
+		{"bytes32", bytes32, "MKR"},
+		{"too short", []byte("abc"), ""},
+		{"nil", nil, ""},
+		{
+			"length past the end",
+			append(abiWord(big.NewInt(32)), abiWord(big.NewInt(100))...),
+			strings.Repeat("\x00", 31) + " ",
+		},
+		{
+			"offset past the end",
+			append(abiWord(big.NewInt(64)), abiWord(big.NewInt(0))...),
+			strings.Repeat("\x00", 31) + "@",
+		},
+		{
+			// start+32+length overflows int64
+			"overflowing length",
+			append(abiWord(big.NewInt(32)), abiWord(maxInt64)...),
+			strings.Repeat("\x00", 31) + " ",
+		},
+		{
+			"overflowing offset",
+			append(abiWord(maxInt64), abiWord(big.NewInt(0))...),
+			strings.TrimRight(string(abiWord(maxInt64)), "\x00"),
+		},
+		{
+			"uint256 offset",
+			append(abiWord(maxUint256), abiWord(big.NewInt(0))...),
+			string(abiWord(maxUint256)),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := decodeABIString(tt.data); got != tt.want {
+				t.Errorf("decodeABIString() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTokenMetadataLookup(t *testing.T) {
+	cache := &tokenMetadataCache{tokens: make(map[string]*TokenMetadata), failed: make(map[string]time.Time)}
+	usdt := &TokenMetadata{Name: "Tether USD", Symbol: "USDT", Decimals: 6}
+	fetches := 0
+	var fetchErr error
+	fetch := func(ctx context.Context, address string) (*TokenMetadata, error) {
+		fetches++
+		return usdt, fetchErr
+	}
+	ctx := context.Background()
+
+	// A failed fetch is not retried until tokenMetadataRetry has passed
+	fetchErr = errors.New("node unavailable")
+	for i := 0; i < 3; i++ {
+		if metadata := cache.lookup(ctx, "TToken", fetch); metadata != nil {
+			t.Fatalf("lookup with a failing fetch = %+v, want nil", metadata)
+		}
+	}
+	if fetches != 1 {
+		t.Errorf("fetched %d times while failing, want 1", fetches)
+	}
+
+	fetchErr = nil
+	cache.failed["TToken"] = time.Now().Add(-time.Second)
+	for i := 0; i < 3; i++ {
+		if metadata := cache.lookup(ctx, "TToken", fetch); metadata != usdt {
+			t.Fatalf("lookup after the retry delay = %+v, want %+v", metadata, usdt)
+		}
+	}
+	if fetches != 2 {
+		t.Errorf("fetched %d times, want 2", fetches)
+	}
+}
@@ -1,12 +1,15 @@
 package scanner
 
 import (
+	"reflect"
+	"strings"
 	"time"
 )
 
 // Transaction represents a parsed TRON transaction
 type Transaction struct {
 	ID             string    `json:"id"`
+	Index          int       `json:"index"` // Position of the transaction in its block
 	Contract       *Contract `json:"contract,omitempty"`
 	Ret            *RetInfo  `json:"ret,omitempty"`
 	Timestamp      time.Time `json:"timestamp"`
@@ -16,6 +19,165 @@ type Transaction struct {
 	Receipt        *Receipt  `json:"receipt,omitempty"`
 	Logs           []LogInfo `json:"logs,omitempty"`
 	Signers        []string  `json:"signers,omitempty"` // All signers for the transaction
+	Raw            *RawData  `json:"raw,omitempty"`     // Serialized protobufs, only when enabled on the scanner
+	// Internal transactions are the calls and transfers made by the executed smart contracts
+	InternalTransactions []InternalTransaction `json:"internal_transactions,omitempty"`
+}
+
+// RawData holds the base64-encoded on-chain protobufs of a transaction
+type RawData struct {
+	Transaction     string `json:"transaction"`
+	TransactionInfo string `json:"transaction_info,omitempty"`
+}
+
+// IsSuccess reports whether the transaction executed successfully.
+// Transactions without a receipt, or with a DEFAULT result (plain transfers), are considered successful.
+func (t *Transaction) IsSuccess() bool {
+	if t.Receipt == nil {
+		return true
+	}
+	switch t.Receipt.Result {
+	case "", "DEFAULT", "SUCCESS":
+		return true
+	default:
+		return false
+	}
+}
+
+// OwnerAddress returns the owner address of the transaction's contract, or an empty string if unknown.
+func (t *Transaction) OwnerAddress() string {
+	owner, _ := t.ParameterValue("owner_address").(string)
+	return owner
+}
+
+// ContractAddress returns the smart contract called or created by the transaction, or an empty string
+// for contract types without one.
+func (t *Transaction) ContractAddress() string {
+	contract, _ := t.ParameterValue("contract_address").(string)
+	return contract
+}
+
+// ParameterValue returns a field of the contract parameter by its JSON name (e.g. "amount"),
+// or nil if the parameter has no such field.
+func (t *Transaction) ParameterValue(key string) interface{} {
+	if t.Contract == nil || t.Contract.Parameter == nil {
+		return nil
+	}
+
+	// Parameters registered by library users may be maps rather than contract structs
+	if parameter, ok := t.Contract.Parameter.(map[string]interface{}); ok {
+		return parameter[key]
+	}
+
+	value := reflect.ValueOf(t.Contract.Parameter)
+	if value.Kind() == reflect.Ptr {
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return nil
+	}
+	for i := 0; i < value.NumField(); i++ {
+		name := strings.Split(value.Type().Field(i).Tag.Get("json"), ",")[0]
+		if name == key && value.Field(i).CanInterface() {
+			return value.Field(i).Interface()
+		}
+	}
+	return nil
+}
+
+// Addresses returns the distinct addresses involved in the transaction: the address fields of the
+// contract parameter (owner, recipient, contract, ...), the emitting contracts of its logs, the
+// address inputs of its decoded events (e.g. TRC20 sender and receiver) and the callers and
+// recipients of its internal transactions.
+func (t *Transaction) Addresses() []string {
+	seen := make(map[string]bool)
+	addresses := make([]string, 0, 4)
+	add := func(address string) {
+		if address != "" && !seen[address] {
+			seen[address] = true
+			addresses = append(addresses, address)
+		}
+	}
+
+	if t.Contract != nil && t.Contract.Parameter != nil {
+		if parameter, ok := t.Contract.Parameter.(map[string]interface{}); ok {
+			for key, value := range parameter {
+				if address, ok := value.(string); ok && strings.HasSuffix(key, "address") {
+					add(address)
+				}
+			}
+		} else {
+			value := reflect.ValueOf(t.Contract.Parameter)
+			if value.Kind() == reflect.Ptr {
+				value = value.Elem()
+			}
+			if value.Kind() == reflect.Struct {
+				for i := 0; i < value.NumField(); i++ {
+					field := value.Field(i)
+					if field.Kind() == reflect.String && strings.HasSuffix(value.Type().Field(i).Name, "Address") {
+						add(field.String())
+					}
+				}
+			}
+		}
+	}
+
+	for _, log := range t.Logs {
+		add(log.Address)
+		for _, input := range log.Inputs {
+			if input.Type == "address" {
+				if address, ok := input.Value.(string); ok {
+					add(address)
+				}
+			}
+		}
+	}
+	for _, internal := range t.InternalTransactions {
+		add(internal.CallerAddress)
+		add(internal.ToAddress)
+	}
+
+	return addresses
+}
+
+// recipientParameters are the contract parameter fields holding the recipient of a transfer, a
+// delegation or a new account.
+var recipientParameters = []string{"to_address", "receiver_address", "account_address"}
+
+// Parties returns the distinct addresses the transaction moves value between: the owner and recipient
+// of its contract, the senders and receivers of its TRC20 transfers and the callers and recipients of
+// its internal transactions. Unlike Addresses, it leaves out the called and emitting contracts.
+func (t *Transaction) Parties() []string {
+	seen := make(map[string]bool)
+	parties := make([]string, 0, 4)
+	add := func(address string) {
+		if address != "" && !seen[address] {
+			seen[address] = true
+			parties = append(parties, address)
+		}
+	}
+
+	add(t.OwnerAddress())
+	for _, key := range recipientParameters {
+		recipient, _ := t.ParameterValue(key).(string)
+		add(recipient)
+	}
+	for i := range t.Logs {
+		if !t.Logs[i].IsTRC20Transfer() {
+			continue
+		}
+		for _, input := range t.Logs[i].Inputs[:2] {
+			if address, ok := input.Value.(string); ok && input.Type == "address" {
+				add(address)
+			}
+		}
+	}
+	for _, internal := range t.InternalTransactions {
+		add(internal.CallerAddress)
+		add(internal.ToAddress)
+	}
+
+	return parties
 }
 
 // RetInfo represents the return information of a transaction
@@ -25,12 +187,21 @@ type RetInfo struct {
 
 // Receipt represents the receipt information of a transaction
 type Receipt struct {
-	EnergyUsage       int64 `json:"energy_usage,omitempty"`
-	EnergyFee         int64 `json:"energy_fee,omitempty"`
-	OriginEnergyUsage int64 `json:"origin_energy_usage,omitempty"`
-	EnergyUsageTotal  int64 `json:"energy_usage_total,omitempty"`
-	NetUsage          int64 `json:"net_usage,omitempty"`
-	NetFee            int64 `json:"net_fee,omitempty"`
+	EnergyUsage       int64  `json:"energy_usage,omitempty"`
+	EnergyFee         int64  `json:"energy_fee,omitempty"`
+	OriginEnergyUsage int64  `json:"origin_energy_usage,omitempty"`
+	EnergyUsageTotal  int64  `json:"energy_usage_total,omitempty"`
+	NetUsage          int64  `json:"net_usage,omitempty"`
+	NetFee            int64  `json:"net_fee,omitempty"`
+	Result            string `json:"result,omitempty"` // Contract execution result, e.g. SUCCESS or REVERT
+}
+
+// InternalTransaction represents a call or transfer made by a smart contract during execution
+type InternalTransaction struct {
+	CallerAddress string `json:"caller_address"`
+	ToAddress     string `json:"to_address"`
+	CallValue     int64  `json:"call_value,omitempty"` // TRX transferred, in sun
+	Rejected      bool   `json:"rejected,omitempty"`
 }
 
 // LogInfo represents a decoded log event
@@ -39,13 +210,23 @@ type LogInfo struct {
 	Signature string       `json:"signature"`
 	Inputs    []EventInput `json:"inputs,omitempty"`
 	Address   string       `json:"address,omitempty"`
+	Token     *TokenInfo   `json:"token,omitempty"` // Token metadata for TRC20 transfers, only when enabled on the scanner
+}
+
+// IsTRC20Transfer reports whether the log is a decoded Transfer(address,address,uint256) event.
+// TRC721 transfers share the signature but index the token ID, and don't match.
+func (l *LogInfo) IsTRC20Transfer() bool {
+	return l.EventName == "Transfer" && len(l.Inputs) == 3 &&
+		l.Inputs[0].Type == "address" && l.Inputs[1].Type == "address" &&
+		l.Inputs[2].Type == "uint256" && !l.Inputs[2].Indexed
 }
 
 // EventInput represents a parameter of a decoded event
 type EventInput struct {
-	Name  string      `json:"name"`
-	Type  string      `json:"type"`
-	Value interface{} `json:"value"`
+	Name    string      `json:"name"`
+	Type    string      `json:"type"`
+	Value   interface{} `json:"value"`
+	Indexed bool        `json:"indexed,omitempty"` // Carried in a topic of the log rather than its data
 }
 
 // Contract represents the contract details
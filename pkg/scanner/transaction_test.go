@@ -0,0 +1,131 @@
+package scanner
+
+import (
+	"math/big"
+	"reflect"
+	"testing"
+)
+
+func TestParties(t *testing.T) {
+	tests := []struct {
+		name string
+		tx   Transaction
+		want []string
+	}{
+		{
+			name: "TRX transfer",
+			tx: Transaction{Contract: &Contract{
+				Type:      "TransferContract",
+				Parameter: TransferContract{OwnerAddress: "TOwner", ToAddress: "TTo", Amount: 1},
+			}},
+			want: []string{"TOwner", "TTo"},
+		},
+		{
+			name: "delegation from a map parameter",
+			tx: Transaction{Contract: &Contract{
+				Type:      "DelegateResourceContract",
+				Parameter: map[string]interface{}{"owner_address": "TOwner", "receiver_address": "TReceiver"},
+			}},
+			want: []string{"TOwner", "TReceiver"},
+		},
+		{
+			name: "TRC20 transfer leaves out the contracts",
+			tx: Transaction{
+				Contract: &Contract{
+					Type:      "TriggerSmartContract",
+					Parameter: &TriggerSmartContract{OwnerAddress: "TOwner", ContractAddress: "TToken"},
+				},
+				Logs: []LogInfo{
+					{
+						EventName: "Transfer",
+						Address:   "TToken",
+						Inputs: []EventInput{
+							{Name: "from", Type: "address", Value: "TOwner"},
+							{Name: "to", Type: "address", Value: "TTo"},
+							{Name: "value", Type: "uint256", Value: big.NewInt(5)},
+						},
+					},
+					{
+						EventName: "Approval",
+						Address:   "TToken",
+						Inputs: []EventInput{
+							{Name: "owner", Type: "address", Value: "TOwner"},
+							{Name: "spender", Type: "address", Value: "TSpender"},
+							{Name: "value", Type: "uint256", Value: big.NewInt(5)},
+						},
+					},
+				},
+			},
+			want: []string{"TOwner", "TTo"},
+		},
+		{
+			name: "internal transactions",
+			tx: Transaction{
+				Contract: &Contract{
+					Type:      "TriggerSmartContract",
+					Parameter: TriggerSmartContract{OwnerAddress: "TOwner", ContractAddress: "TRouter"},
+				},
+				InternalTransactions: []InternalTransaction{
+					{CallerAddress: "TRouter", ToAddress: "TPool"},
+					{CallerAddress: "TPool", ToAddress: "TOwner"},
+				},
+			},
+			want: []string{"TOwner", "TRouter", "TPool"},
+		},
+		{
+			name: "no contract",
+			tx:   Transaction{},
+			want: []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.tx.Parties(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parties() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsTRC20Transfer(t *testing.T) {
+	transfer := func(types ...string) []EventInput {
+		inputs := make([]EventInput, len(types))
+		for i, typ := range types {
+			inputs[i] = EventInput{Type: typ, Value: "x"}
+		}
+		return inputs
+	}
+	trc721 := transfer("address", "address", "uint256")
+	for i := range trc721 {
+		trc721[i].Indexed = true
+	}
+
+	tests := []struct {
+		name string
+		log  LogInfo
+		want bool
+	}{
+		{name: "TRC20 transfer", log: LogInfo{EventName: "Transfer", Inputs: transfer("address", "address", "uint256")}, want: true},
+		{name: "TRC721 transfer of an indexed token ID", log: LogInfo{EventName: "Transfer", Inputs: trc721}},
+		{name: "other input types", log: LogInfo{EventName: "Transfer", Inputs: transfer("address", "address", "bytes32")}},
+		{name: "address value", log: LogInfo{EventName: "Transfer", Inputs: transfer("address", "uint256", "uint256")}},
+		{name: "two inputs", log: LogInfo{EventName: "Transfer", Inputs: transfer("address", "uint256")}},
+		{name: "approval", log: LogInfo{EventName: "Approval", Inputs: transfer("address", "address", "uint256")}},
+	}
+	block := &Block{}
+	var want int64
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.log.IsTRC20Transfer(); got != tt.want {
+				t.Errorf("IsTRC20Transfer() = %v, want %v", got, tt.want)
+			}
+		})
+		block.Transactions = append(block.Transactions, Transaction{Logs: []LogInfo{tt.log}})
+		if tt.want {
+			want++
+		}
+	}
+	if got := block.Stats().TRC20Transfers; got != want {
+		t.Errorf("Stats().TRC20Transfers = %d, want %d", got, want)
+	}
+}
@@ -1,9 +1,14 @@
 package scanner
 
 import (
+	"encoding/base64"
+	"fmt"
+
 	"github.com/kslamph/tronlib/pb/api"
+	"github.com/kslamph/tronlib/pb/core"
 	"github.com/kslamph/tronlib/pkg/types"
 	"github.com/kslamph/tronlib/pkg/utils"
+	"google.golang.org/protobuf/proto"
 )
 
 // only use this func if the addr []byte is guaranteed to be a valid address
@@ -33,3 +38,26 @@ func recoverSignersFromTransaction(tx *api.TransactionExtention) ([]string, erro
 
 	return signers, nil
 }
+
+// rawMarshal serializes the raw protobufs deterministically, so the same transaction always has the same bytes
+var rawMarshal = proto.MarshalOptions{Deterministic: true}
+
+// rawData serializes the on-chain Transaction and TransactionInfo protobufs as base64
+func rawData(tx *api.TransactionExtention, txInfo *core.TransactionInfo) (*RawData, error) {
+	raw := &RawData{}
+	if tx.Transaction != nil {
+		data, err := rawMarshal.Marshal(tx.Transaction)
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize transaction %x: %w", tx.Txid, err)
+		}
+		raw.Transaction = base64.StdEncoding.EncodeToString(data)
+	}
+	if txInfo != nil {
+		data, err := rawMarshal.Marshal(txInfo)
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize info of transaction %x: %w", tx.Txid, err)
+		}
+		raw.TransactionInfo = base64.StdEncoding.EncodeToString(data)
+	}
+	return raw, nil
+}
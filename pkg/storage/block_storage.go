@@ -3,6 +3,7 @@ package storage
 import (
 	"context"
 	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/go-redis/redis/v8"
@@ -22,13 +23,21 @@ func NewBlockProcessedStorage(client *redis.Client, key string) *BlockProcessedS
 	}
 }
 
+// Key returns the sorted set of processed blocks: block numbers scored by the time they were processed.
+func (s *BlockProcessedStorage) Key() string {
+	return s.key
+}
+
 // IsProcessed checks if a block has already been processed.
 func (s *BlockProcessedStorage) IsProcessed(ctx context.Context, blockNumber int64) (bool, error) {
-	count, err := s.client.ZCount(ctx, s.key, fmt.Sprintf("%d", blockNumber), fmt.Sprintf("%d", blockNumber)).Result()
-	if err != nil && err != redis.Nil {
+	err := s.client.ZScore(ctx, s.key, strconv.FormatInt(blockNumber, 10)).Err()
+	if err == redis.Nil {
+		return false, nil
+	}
+	if err != nil {
 		return false, err
 	}
-	return count > 0, nil
+	return true, nil
 }
 
 // MarkProcessed marks a block as processed with a 7-day expiration using ZSET.
@@ -11,21 +11,26 @@ import (
 	"github.com/sunbankio/tronevents/pkg/storage"
 )
 
+// PublishedFunc is called with each block a worker published.
+type PublishedFunc func(ctx context.Context, block *scanner.Block)
+
 // Handler processes Asynq tasks
 type Handler struct {
 	tronScanner           *scanner.Scanner
-	publisher             *publisher.EventPublisher
+	publisher             publisher.Sink
 	logger                *logging.Logger
 	blockProcessedStorage *storage.BlockProcessedStorage
+	onPublished           PublishedFunc
 }
 
-// NewHandler creates a new task handler
-func NewHandler(tronScanner *scanner.Scanner, publisher *publisher.EventPublisher, blockProcessedStorage *storage.BlockProcessedStorage, logger *logging.Logger) *Handler {
+// NewHandler creates a new task handler. onPublished, if not nil, is called after each published block.
+func NewHandler(tronScanner *scanner.Scanner, publisher publisher.Sink, blockProcessedStorage *storage.BlockProcessedStorage, onPublished PublishedFunc, logger *logging.Logger) *Handler {
 	return &Handler{
 		tronScanner:           tronScanner,
 		publisher:             publisher,
 		logger:                logger,
 		blockProcessedStorage: blockProcessedStorage,
+		onPublished:           onPublished,
 	}
 }
 
@@ -56,34 +61,32 @@ func (h *Handler) HandleTask(ctx context.Context, t *asynq.Task) error {
 	}
 
 	// Get transactions for this specific block
-	_, _, transactions, err := h.tronScanner.Scan(ctx, blockNumber)
+	block, err := h.tronScanner.ScanBlock(ctx, blockNumber)
 	if err != nil {
 		h.logger.Errorf("Failed to get transactions for block %d: %v", blockNumber, err)
 		return err
 	}
+	transactions := block.Transactions
 
 	h.logger.Debugf("Retrieved %d transactions for block %d", len(transactions), blockNumber)
 
-	// Publish these transactions to the Redis stream in batch
-	// Convert slice of values to slice of pointers for batch publishing
-	transactionPointers := make([]*scanner.Transaction, len(transactions))
-	for i := range transactions {
-		transactionPointers[i] = &transactions[i]
-	}
-
-	if err := h.publisher.PublishBatch(context.Background(), transactionPointers); err != nil {
+	// Publish these transactions to the sinks in batch and mark the block as processed, unless the
+	// main loop processed it in the meantime; worker blocks arrive out of order
+	published, err := publisher.PublishOnce(publisher.WithBacklog(ctx), h.publisher, block, h.blockProcessedStorage)
+	if err != nil {
 		h.logger.Errorf("Failed to publish batch of %d transactions for block %d: %v", len(transactions), blockNumber, err)
 		return err
 	}
+	if !published {
+		h.logger.Debugf("Block %d already processed, skipping", blockNumber)
+		return nil
+	}
+	if h.onPublished != nil {
+		h.onPublished(ctx, block)
+	}
 	publishedCount := len(transactions)
 	errorCount := 0
 
-	// Mark the block as processed to prevent duplicate processing
-	if err := h.blockProcessedStorage.MarkProcessed(ctx, blockNumber); err != nil {
-		h.logger.Errorf("[WORKER] Failed to mark block %d as processed: %v", blockNumber, err)
-		return err
-	}
-
 	h.logger.Infof("[WORKER] Block %d scanned, published %d transactions, %d errors", blockNumber, publishedCount, errorCount)
 
 	return nil
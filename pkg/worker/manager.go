@@ -28,3 +28,8 @@ func (m *Manager) StartWithMux(mux *asynq.ServeMux) error {
 func (m *Manager) Stop() {
 	m.server.Stop()
 }
+
+// Shutdown stops the worker manager and waits for in-flight tasks to finish.
+func (m *Manager) Shutdown() {
+	m.server.Shutdown()
+}